@@ -42,6 +42,6 @@ func core() error {
 		return err
 	}
 	logger.Info("start handler")
-	lambda.StartWithOptions(handler.Do, lambda.WithContext(ctx))
+	lambda.StartWithOptions(handler.Handle, lambda.WithContext(ctx))
 	return nil
 }
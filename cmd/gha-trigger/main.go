@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gha-trigger/gha-trigger/pkg/cli"
+)
+
+var (
+	version = ""
+	commit  = "" //nolint:gochecknoglobals
+	date    = "" //nolint:gochecknoglobals
+)
+
+func main() {
+	if code := core(); code != 0 {
+		os.Exit(code)
+	}
+}
+
+func core() int {
+	runner := cli.Runner{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		LDFlags: &cli.LDFlags{
+			Version: version,
+			Commit:  commit,
+			Date:    date,
+		},
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := runner.Run(ctx, os.Args...); err != nil {
+		return 1
+	}
+	return 0
+}
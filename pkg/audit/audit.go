@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"go.uber.org/zap"
+)
+
+// Sink stores audit records durably.
+type Sink interface {
+	Write(ctx context.Context, rec *Record) error
+}
+
+const (
+	ActionWorkflowDispatch = "workflow_dispatch"
+	ActionRerunWorkflow    = "rerun_workflow"
+	ActionRerunFailedJobs  = "rerun_failed_jobs"
+	ActionCancelWorkflow   = "cancel_workflow"
+	ActionRerunJob         = "rerun_job"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultDryRun  = "dry_run"
+)
+
+// Record is who caused what to run.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	DeliveryID string    `json:"delivery_id,omitempty"`
+	Event      string    `json:"event"`
+	// EventAction is the activity type of the event such as opened
+	EventAction string `json:"event_action,omitempty"`
+	// Sender is the user who triggered the event. For slash commands, it's the commenter
+	Sender     string `json:"sender,omitempty"`
+	SourceRepo string `json:"source_repo"`
+	SourceSHA  string `json:"source_sha,omitempty"`
+	// MatchedEvent is the config.Event matching the event.
+	// It's nil for schedules and slash commands.
+	MatchedEvent *MatchedEvent `json:"matched_event,omitempty"`
+	TargetRepo   string        `json:"target_repo"`
+	Workflow     string        `json:"workflow,omitempty"`
+	Ref          string        `json:"ref,omitempty"`
+	// InputsHash is the SHA256 of workflow inputs. Inputs themselves aren't recorded because they're large
+	InputsHash string `json:"inputs_hash,omitempty"`
+	// Command, RunIDs, and JobIDs are set for slash commands.
+	// RunIDs and JobIDs are IDs which are affected by the command successfully.
+	Command string  `json:"command,omitempty"`
+	RunIDs  []int64 `json:"run_ids,omitempty"`
+	JobIDs  []int64 `json:"job_ids,omitempty"`
+	Result  string  `json:"result"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// MatchedEvent identifies a config.Event.
+// The index of config.Repo.Events isn't recorded because it changes when the configuration is reloaded or merged with the repository's configuration file.
+type MatchedEvent struct {
+	// Events are the event types of the conditions
+	Events []string `json:"events,omitempty"`
+	// Ref and RefFallback are the configured values, so Ref may be a template
+	Ref            string `json:"ref,omitempty"`
+	RefFallback    string `json:"ref_fallback,omitempty"`
+	OnFromWorkflow bool   `json:"on_from_workflow,omitempty"`
+}
+
+// NewRecord creates a record from the event.
+func NewRecord(ev *domain.Event, action string) *Record {
+	payload := ev.Payload
+	return &Record{
+		Time:        time.Now().UTC(),
+		Action:      action,
+		DeliveryID:  ev.DeliveryID(),
+		Event:       ev.Type,
+		EventAction: payload.Action,
+		Sender:      payload.Sender.GetLogin(),
+		SourceRepo:  payload.Repo.GetFullName(),
+		SourceSHA:   getSHA(payload),
+	}
+}
+
+func getSHA(payload *domain.Payload) string {
+	if pr := payload.PullRequest; pr != nil {
+		return pr.GetHead().GetSHA()
+	}
+	if mg := payload.MergeGroup; mg != nil {
+		return mg.GetHeadSHA()
+	}
+	if payload.After != "" {
+		return payload.After
+	}
+	return payload.HeadCommit.GetID()
+}
+
+// SetResult sets the result by the error.
+func (rec *Record) SetResult(err error) {
+	if err != nil {
+		rec.Result = ResultFailure
+		rec.Error = err.Error()
+		return
+	}
+	rec.Result = ResultSuccess
+}
+
+// HashInputs returns the SHA256 of JSON encoded workflow inputs.
+func HashInputs(inputs map[string]interface{}) (string, error) {
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("marshal inputs as JSON: %w", err)
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Write writes the record to the sink.
+// Audit logs are recorded after actions are run, so the failure is logged instead of failing the request.
+func Write(ctx context.Context, logger *zap.Logger, sink Sink, rec *Record) {
+	if sink == nil {
+		return
+	}
+	if err := sink.Write(ctx, rec); err != nil {
+		logger.Error("write an audit log", zap.Error(err))
+	}
+}
+
+type AWSClient interface {
+	S3Client
+	CloudWatchLogsClient
+}
+
+// New creates a sink. If cfg is nil, nil is returned.
+func New(cfg *config.Audit, awsClient AWSClient) (Sink, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	switch cfg.Type {
+	case config.AuditTypeFile:
+		return NewFile(cfg.Path), nil
+	case config.AuditTypeS3:
+		return NewS3(awsClient, cfg.Bucket, cfg.KeyPrefix), nil
+	case config.AuditTypeCloudWatchLogs:
+		return NewCloudWatchLogs(awsClient, cfg.LogGroupName, cfg.LogStreamName), nil
+	default:
+		return nil, fmt.Errorf("audit type is invalid: %s", cfg.Type)
+	}
+}
+
+// aws.Client implements AWSClient
+var _ AWSClient = &aws.Client{}
@@ -0,0 +1,169 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFile_Write(t *testing.T) {
+	t.Parallel()
+	p := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink := audit.NewFile(p)
+	ctx := context.Background()
+	recs := []*audit.Record{
+		{
+			Time:       time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			Action:     audit.ActionWorkflowDispatch,
+			DeliveryID: "xxx",
+			Event:      "push",
+			SourceRepo: "gha-trigger/example",
+			TargetRepo: "gha-trigger/example-ci",
+			Workflow:   "test.yaml",
+			Result:     audit.ResultSuccess,
+		},
+		{
+			Time:       time.Date(2023, 1, 2, 3, 4, 6, 0, time.UTC),
+			Action:     audit.ActionCancelWorkflow,
+			Event:      "issue_comment",
+			Sender:     "octocat",
+			SourceRepo: "gha-trigger/example",
+			TargetRepo: "gha-trigger/example-ci",
+			Command:    "/cancel",
+			RunIDs:     []int64{1, 2},
+			Result:     audit.ResultSuccess,
+		},
+	}
+	for _, rec := range recs {
+		if err := sink.Write(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	got := make([]*audit.Record, len(lines))
+	for i, line := range lines {
+		rec := &audit.Record{}
+		if err := json.Unmarshal([]byte(line), rec); err != nil {
+			t.Fatal(err)
+		}
+		got[i] = rec
+	}
+	if diff := cmp.Diff(recs, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+type s3Client struct {
+	input *aws.PutObjectInput
+}
+
+func (c *s3Client) PutObjectWithContext(ctx aws.Context, input *aws.PutObjectInput, opts ...aws.Option) (*aws.PutObjectOutput, error) {
+	c.input = input
+	return &aws.PutObjectOutput{}, nil
+}
+
+func TestS3_Write(t *testing.T) {
+	t.Parallel()
+	client := &s3Client{}
+	sink := audit.NewS3(client, "audit-bucket", "gha-trigger/")
+	rec := &audit.Record{
+		Time:       time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Action:     audit.ActionWorkflowDispatch,
+		Event:      "push",
+		SourceRepo: "gha-trigger/example",
+		MatchedEvent: &audit.MatchedEvent{
+			Events: []string{"push"},
+			Ref:    "main",
+		},
+		TargetRepo: "gha-trigger/example-ci",
+		Workflow:   "test.yaml",
+		Result:     audit.ResultSuccess,
+	}
+	if err := sink.Write(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	input := client.input
+	if bucket := *input.Bucket; bucket != "audit-bucket" {
+		t.Fatalf("bucket: wanted audit-bucket, got %s", bucket)
+	}
+	if key := *input.Key; !strings.HasPrefix(key, "gha-trigger/2023/01/02/030405.000000000-") || !strings.HasSuffix(key, ".json") {
+		t.Fatalf("key is unexpected: %s", key)
+	}
+	b, err := io.ReadAll(input.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &audit.Record{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(rec, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+type cloudWatchLogsClient struct {
+	streamExists bool
+	events       []string
+}
+
+func (c *cloudWatchLogsClient) PutLogEventsWithContext(ctx aws.Context, input *aws.PutLogEventsInput, opts ...aws.Option) (*aws.PutLogEventsOutput, error) {
+	if !c.streamExists {
+		return nil, awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "the log stream doesn't exist", nil)
+	}
+	for _, ev := range input.LogEvents {
+		c.events = append(c.events, *ev.Message)
+	}
+	return &aws.PutLogEventsOutput{}, nil
+}
+
+func (c *cloudWatchLogsClient) CreateLogStreamWithContext(ctx aws.Context, input *aws.CreateLogStreamInput, opts ...aws.Option) (*aws.CreateLogStreamOutput, error) {
+	c.streamExists = true
+	return &aws.CreateLogStreamOutput{}, nil
+}
+
+func TestCloudWatchLogs_Write(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		streamExists bool
+	}{
+		{
+			name:         "normal",
+			streamExists: true,
+		},
+		{
+			name: "create a log stream",
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := &cloudWatchLogsClient{streamExists: tt.streamExists}
+			sink := audit.NewCloudWatchLogs(client, "gha-trigger", "audit")
+			if err := sink.Write(ctx, &audit.Record{Action: audit.ActionWorkflowDispatch}); err != nil {
+				t.Fatal(err)
+			}
+			if len(client.events) != 1 {
+				t.Fatalf("the number of log events should be 1: %d", len(client.events))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type CloudWatchLogsClient interface {
+	PutLogEventsWithContext(ctx aws.Context, input *aws.PutLogEventsInput, opts ...aws.Option) (*aws.PutLogEventsOutput, error)
+	CreateLogStreamWithContext(ctx aws.Context, input *aws.CreateLogStreamInput, opts ...aws.Option) (*aws.CreateLogStreamOutput, error)
+}
+
+// CloudWatchLogs puts a record as a log event.
+// If the log stream doesn't exist, it's created.
+type CloudWatchLogs struct {
+	client        CloudWatchLogsClient
+	logGroupName  string
+	logStreamName string
+	mu            sync.Mutex
+}
+
+func NewCloudWatchLogs(client CloudWatchLogsClient, logGroupName, logStreamName string) *CloudWatchLogs {
+	return &CloudWatchLogs{
+		client:        client,
+		logGroupName:  logGroupName,
+		logStreamName: logStreamName,
+	}
+}
+
+func (c *CloudWatchLogs) Write(ctx context.Context, rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal an audit record as JSON: %w", err)
+	}
+	input := &aws.PutLogEventsInput{
+		LogGroupName:  util.StrP(c.logGroupName),
+		LogStreamName: util.StrP(c.logStreamName),
+		LogEvents: []*aws.InputLogEvent{
+			{
+				Message:   util.StrP(string(b)),
+				Timestamp: util.Int64P(rec.Time.UnixMilli()),
+			},
+		},
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.client.PutLogEventsWithContext(ctx, input)
+	if err == nil {
+		return nil
+	}
+	if !aws.IsResourceNotFound(err) {
+		return fmt.Errorf("put an audit record to Amazon CloudWatch Logs: %w", err)
+	}
+	if _, err := c.client.CreateLogStreamWithContext(ctx, &aws.CreateLogStreamInput{
+		LogGroupName:  util.StrP(c.logGroupName),
+		LogStreamName: util.StrP(c.logStreamName),
+	}); err != nil {
+		return fmt.Errorf("create a log stream of Amazon CloudWatch Logs: %w", err)
+	}
+	if _, err := c.client.PutLogEventsWithContext(ctx, input); err != nil {
+		return fmt.Errorf("put an audit record to Amazon CloudWatch Logs: %w", err)
+	}
+	return nil
+}
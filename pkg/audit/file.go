@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// File appends records to a JSON Lines file.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFile(p string) *File {
+	return &File{
+		path: p,
+	}
+}
+
+func (f *File) Write(ctx context.Context, rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal an audit record as JSON: %w", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gomnd
+	if err != nil {
+		return fmt.Errorf("open an audit log file: %w", err)
+	}
+	if _, err := file.Write(append(b, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("write an audit record to the file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close an audit log file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type S3Client interface {
+	PutObjectWithContext(ctx aws.Context, input *aws.PutObjectInput, opts ...aws.Option) (*aws.PutObjectOutput, error)
+}
+
+// S3 creates an object per record because Amazon S3 can't append data to an object.
+// The key is <key prefix><date>/<time>-<random>.json, so records can be listed in order.
+type S3 struct {
+	client    S3Client
+	bucket    string
+	keyPrefix string
+}
+
+func NewS3(client S3Client, bucket, keyPrefix string) *S3 {
+	return &S3{
+		client:    client,
+		bucket:    bucket,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *S3) Write(ctx context.Context, rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal an audit record as JSON: %w", err)
+	}
+	suffix, err := randomHex()
+	if err != nil {
+		return err
+	}
+	key := s.keyPrefix + rec.Time.Format("2006/01/02/150405.000000000") + "-" + suffix + ".json"
+	if _, err := s.client.PutObjectWithContext(ctx, &aws.PutObjectInput{
+		Bucket:      util.StrP(s.bucket),
+		Key:         util.StrP(key),
+		Body:        bytes.NewReader(b),
+		ContentType: util.StrP("application/json"),
+	}); err != nil {
+		return fmt.Errorf("put an audit record to Amazon S3: %w", err)
+	}
+	return nil
+}
+
+func randomHex() (string, error) {
+	b := make([]byte, 8) //nolint:gomnd
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate a random string: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
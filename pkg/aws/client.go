@@ -1,21 +1,51 @@
 package aws
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
 )
 
 type Client struct {
 	secretsManager SecretsManager
+	sqs            SQS
+	s3             S3
+	ssm            SSM
+	cloudWatchLogs CloudWatchLogs
 }
 
 type SecretsManager interface {
 	GetSecretValueWithContext(ctx aws.Context, input *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error)
 }
 
+type SQS interface {
+	SendMessageWithContext(ctx aws.Context, input *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error)
+}
+
+type S3 interface {
+	GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error)
+	PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error)
+}
+
+type SSM interface {
+	GetParameterWithContext(ctx aws.Context, input *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error)
+}
+
+type CloudWatchLogs interface {
+	PutLogEventsWithContext(ctx aws.Context, input *cloudwatchlogs.PutLogEventsInput, opts ...request.Option) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogStreamWithContext(ctx aws.Context, input *cloudwatchlogs.CreateLogStreamInput, opts ...request.Option) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
 func New(cfg *config.AWS) *Client {
 	sess := session.Must(session.NewSession())
 	awsCfg := aws.NewConfig()
@@ -24,16 +54,76 @@ func New(cfg *config.AWS) *Client {
 	}
 	return &Client{
 		secretsManager: secretsmanager.New(sess, awsCfg),
+		sqs:            sqs.New(sess, awsCfg),
+		s3:             s3.New(sess, awsCfg),
+		ssm:            ssm.New(sess, awsCfg),
+		cloudWatchLogs: cloudwatchlogs.New(sess, awsCfg),
 	}
 }
 
 type (
-	GetSecretValueInput  = secretsmanager.GetSecretValueInput
-	GetSecretValueOutput = secretsmanager.GetSecretValueOutput
-	Option               = request.Option
-	Context              = aws.Context
+	GetSecretValueInput   = secretsmanager.GetSecretValueInput
+	GetSecretValueOutput  = secretsmanager.GetSecretValueOutput
+	SendMessageInput      = sqs.SendMessageInput
+	SendMessageOutput     = sqs.SendMessageOutput
+	GetObjectInput        = s3.GetObjectInput
+	GetObjectOutput       = s3.GetObjectOutput
+	PutObjectInput        = s3.PutObjectInput
+	PutObjectOutput       = s3.PutObjectOutput
+	GetParameterInput     = ssm.GetParameterInput
+	GetParameterOutput    = ssm.GetParameterOutput
+	PutLogEventsInput     = cloudwatchlogs.PutLogEventsInput
+	PutLogEventsOutput    = cloudwatchlogs.PutLogEventsOutput
+	CreateLogStreamInput  = cloudwatchlogs.CreateLogStreamInput
+	CreateLogStreamOutput = cloudwatchlogs.CreateLogStreamOutput
+	InputLogEvent         = cloudwatchlogs.InputLogEvent
+	Option                = request.Option
+	Context               = aws.Context
 )
 
 func (cl *Client) GetSecretValueWithContext(ctx aws.Context, input *GetSecretValueInput, opts ...Option) (*GetSecretValueOutput, error) {
 	return cl.secretsManager.GetSecretValueWithContext(ctx, input, opts...)
 }
+
+func (cl *Client) SendMessageWithContext(ctx aws.Context, input *SendMessageInput, opts ...Option) (*SendMessageOutput, error) {
+	return cl.sqs.SendMessageWithContext(ctx, input, opts...)
+}
+
+func (cl *Client) GetObjectWithContext(ctx aws.Context, input *GetObjectInput, opts ...Option) (*GetObjectOutput, error) {
+	return cl.s3.GetObjectWithContext(ctx, input, opts...)
+}
+
+func (cl *Client) GetParameterWithContext(ctx aws.Context, input *GetParameterInput, opts ...Option) (*GetParameterOutput, error) {
+	return cl.ssm.GetParameterWithContext(ctx, input, opts...)
+}
+
+func (cl *Client) PutObjectWithContext(ctx aws.Context, input *PutObjectInput, opts ...Option) (*PutObjectOutput, error) {
+	return cl.s3.PutObjectWithContext(ctx, input, opts...)
+}
+
+func (cl *Client) PutLogEventsWithContext(ctx aws.Context, input *PutLogEventsInput, opts ...Option) (*PutLogEventsOutput, error) {
+	return cl.cloudWatchLogs.PutLogEventsWithContext(ctx, input, opts...)
+}
+
+func (cl *Client) CreateLogStreamWithContext(ctx aws.Context, input *CreateLogStreamInput, opts ...Option) (*CreateLogStreamOutput, error) {
+	return cl.cloudWatchLogs.CreateLogStreamWithContext(ctx, input, opts...)
+}
+
+// IsNotModified returns true if the request failed with the status code 304 Not Modified.
+// Amazon S3 returns it when the condition If-None-Match isn't satisfied.
+func IsNotModified(err error) bool {
+	var reqErr awserr.RequestFailure
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+	return reqErr.StatusCode() == http.StatusNotModified
+}
+
+// IsResourceNotFound returns true if the resource such as CloudWatch Logs' log stream doesn't exist.
+func IsResourceNotFound(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	return awsErr.Code() == cloudwatchlogs.ErrCodeResourceNotFoundException
+}
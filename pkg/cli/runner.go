@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+type Runner struct {
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	LDFlags *LDFlags
+}
+
+type LDFlags struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+func (runner *Runner) Run(ctx context.Context, args ...string) error {
+	app := cli.App{
+		Name:      "gha-trigger",
+		Usage:     "Test and validate gha-trigger's configuration offline",
+		Version:   runner.LDFlags.Version + " (" + runner.LDFlags.Commit + ")",
+		Writer:    runner.Stdout,
+		ErrWriter: runner.Stderr,
+		Commands: []*cli.Command{
+			runner.newTestCommand(),
+			runner.newValidateCommand(),
+		},
+	}
+	if err := app.RunContext(ctx, args); err != nil {
+		fmt.Fprintln(runner.Stderr, "ERROR:", err)
+		return err
+	}
+	return nil
+}
+
+func readConfig(p string) (*config.Config, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("read a configuration file: %w", err)
+	}
+	cfg := &config.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parse the configuration as YAML: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/route"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+func (runner *Runner) newTestCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "test",
+		Usage:     "Replay recorded webhook payloads against the configuration",
+		ArgsUsage: "<test case file> [<test case file> ...]",
+		Description: `Match recorded webhook payloads with the configuration offline and output which workflows would be run and why.
+
+A test case file is a YAML file.
+
+  event: pull_request # required. The value of the header X-GitHub-Event
+  payload: pull_request.json # required. A webhook payload
+  changed_files: # Changed files returned instead of GitHub API
+    - README.md
+  pull_request: pr.json # The pull request which replaces the payload's one, e.g. to set changed_files and base and head SHAs
+  golden: pull_request.golden # The expected output
+
+Relative paths are relative to the test case file.
+GitHub API isn't called. If the configuration has paths conditions, changed_files must be set.
+If golden is set, the output is compared with the golden file and the command fails if they are different.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "config",
+				Aliases:  []string{"c"},
+				Usage:    "configuration file path",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "update",
+				Usage: "update golden files",
+			},
+		},
+		Action: runner.testAction,
+	}
+}
+
+type testCase struct {
+	Event        string
+	Payload      string
+	ChangedFiles []string `yaml:"changed_files"`
+	PullRequest  string   `yaml:"pull_request"`
+	Golden       string
+}
+
+var errGoldenMismatch = errors.New("the output is different from the golden file")
+
+func (runner *Runner) testAction(c *cli.Context) error {
+	cfg, err := readConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	if err := config.Validate(cfg); err != nil {
+		return fmt.Errorf("configuration is invalid: %w", err)
+	}
+	if err := config.Prepare(cfg); err != nil {
+		return err //nolint:wrapcheck
+	}
+	for _, repo := range cfg.Repos {
+		for _, ev := range repo.Events {
+			if ev.OnFromWorkflow {
+				return fmt.Errorf("on_from_workflow isn't supported offline: %s/%s %s", repo.RepoOwner, repo.RepoName, ev.Workflow.WorkflowFileName)
+			}
+		}
+	}
+	if err := config.Init(cfg); err != nil {
+		return fmt.Errorf("initialize configuration: %w", err)
+	}
+	failed := false
+	for _, p := range c.Args().Slice() {
+		if err := runner.runTestCase(c.Context, cfg, p, c.Bool("update")); err != nil {
+			if !errors.Is(err, errGoldenMismatch) {
+				return fmt.Errorf("run a test case %s: %w", p, err)
+			}
+			failed = true
+		}
+	}
+	if failed {
+		return errGoldenMismatch
+	}
+	return nil
+}
+
+func (runner *Runner) runTestCase(ctx context.Context, cfg *config.Config, p string, update bool) error {
+	tc := &testCase{}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("read a test case file: %w", err)
+	}
+	if err := yaml.Unmarshal(b, tc); err != nil {
+		return fmt.Errorf("parse a test case file as YAML: %w", err)
+	}
+	if tc.Event == "" || tc.Payload == "" {
+		return errors.New("event and payload are required")
+	}
+	dir := filepath.Dir(p)
+	body, err := os.ReadFile(joinPath(dir, tc.Payload))
+	if err != nil {
+		return fmt.Errorf("read a payload file: %w", err)
+	}
+	ev, err := domain.ParseEvent(tc.Event, body)
+	if err != nil {
+		return err
+	}
+	if tc.PullRequest != "" {
+		pr, err := readPullRequest(joinPath(dir, tc.PullRequest))
+		if err != nil {
+			return err
+		}
+		ev.Payload.PullRequest = pr
+	}
+	ev.GitHub = newFakeGitHub(tc.ChangedFiles)
+
+	buf := &bytes.Buffer{}
+	if err := explain(ctx, buf, cfg, ev); err != nil {
+		return err
+	}
+	out := buf.Bytes()
+	fmt.Fprintf(runner.Stdout, "# %s\n", p)
+	if _, err := runner.Stdout.Write(out); err != nil {
+		return fmt.Errorf("output the result: %w", err)
+	}
+	if tc.Golden == "" {
+		return nil
+	}
+	goldenPath := joinPath(dir, tc.Golden)
+	if update {
+		if err := os.WriteFile(goldenPath, out, 0o644); err != nil { //nolint:gosec,gomnd
+			return fmt.Errorf("update a golden file: %w", err)
+		}
+		return nil
+	}
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		return fmt.Errorf("read a golden file: %w", err)
+	}
+	if !bytes.Equal(golden, out) {
+		fmt.Fprintf(runner.Stderr, "[FAIL] %s: the output is different from the golden file %s\n--- expected\n%s", p, goldenPath, golden)
+		return errGoldenMismatch
+	}
+	return nil
+}
+
+func readPullRequest(p string) (*github.PullRequest, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("read a pull request file: %w", err)
+	}
+	pr := &github.PullRequest{}
+	if err := json.Unmarshal(b, pr); err != nil {
+		return nil, fmt.Errorf("parse a pull request file as JSON: %w", err)
+	}
+	return pr, nil
+}
+
+func joinPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+func explain(ctx context.Context, w io.Writer, cfg *config.Config, ev *domain.Event) error {
+	owner := ev.Payload.Repo.GetOwner().GetLogin()
+	repoName := ev.Payload.Repo.GetName()
+	fmt.Fprintf(w, "event: %s", ev.Type)
+	if ev.Payload.Action != "" {
+		fmt.Fprintf(w, " (%s)", ev.Payload.Action)
+	}
+	fmt.Fprintf(w, "\nrepository: %s/%s\n", owner, repoName)
+	repoCfg, err := config.GetRepo(cfg.Repos, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("get the repository config: %w", err)
+	}
+	if repoCfg == nil {
+		fmt.Fprintln(w, "repository config isn't found")
+		return nil
+	}
+	traces, err := route.Explain(ctx, ev, repoCfg)
+	if err != nil {
+		return fmt.Errorf("match the event: %w", err)
+	}
+	for i, trace := range traces {
+		result := "not matched"
+		if trace.Matched {
+			result = "matched"
+		}
+		fmt.Fprintf(w, "events[%d] %s: %s\n", i, trace.Workflow.WorkflowFileName, result)
+		if len(trace.Matches) == 0 {
+			fmt.Fprintln(w, "  no match condition")
+		}
+		for j, mt := range trace.Matches {
+			fmt.Fprintf(w, "  matches[%d]: %s\n", j, passOrFail(mt.Matched))
+			for _, m := range mt.Matchers {
+				fmt.Fprintf(w, "    %s: %s%s\n", m.Name, passOrFail(m.Matched), formatInput(m.Input))
+			}
+		}
+	}
+	return nil
+}
+
+// formatInput returns the value compared by a matcher such as the branch and changed files.
+// Changed files are sorted because their order isn't stable.
+func formatInput(input interface{}) string {
+	switch v := input.(type) {
+	case nil:
+		return ""
+	case []string:
+		files := make([]string, len(v))
+		copy(files, v)
+		sort.Strings(files)
+		return " (input: [" + strings.Join(files, ", ") + "])"
+	default:
+		return fmt.Sprintf(" (input: %v)", v)
+	}
+}
+
+func passOrFail(f bool) string {
+	if f {
+		return "pass"
+	}
+	return "fail"
+}
+
+var errChangedFilesNotSet = errors.New("changed_files must be set in the test case to match paths")
+
+// fakeGitHub is used instead of GitHub API.
+// It returns changed files of the test case for any API, so the changed files are got in the same way as runtime.
+type fakeGitHub struct {
+	// files is nil if changed_files isn't set in the test case
+	files []*github.CommitFile
+}
+
+func newFakeGitHub(changedFiles []string) *fakeGitHub {
+	if changedFiles == nil {
+		return &fakeGitHub{}
+	}
+	files := make([]*github.CommitFile, len(changedFiles))
+	for i, f := range changedFiles {
+		f := f
+		files[i] = &github.CommitFile{
+			Filename: &f,
+		}
+	}
+	return &fakeGitHub{
+		files: files,
+	}
+}
+
+func (gh *fakeGitHub) ListPRFiles(ctx context.Context, param *github.ParamsListPRFiles) ([]*github.CommitFile, *github.Response, error) {
+	if gh.files == nil {
+		return nil, nil, errChangedFilesNotSet
+	}
+	return gh.files, nil, nil
+}
+
+func (gh *fakeGitHub) GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error) {
+	if gh.files == nil {
+		return nil, nil, errChangedFilesNotSet
+	}
+	return &github.RepositoryCommit{
+		Files: gh.files,
+	}, nil, nil
+}
+
+func (gh *fakeGitHub) CompareCommitFiles(ctx context.Context, owner, repo, base, head string) ([]*github.CommitFile, *github.Response, error) {
+	if gh.files == nil {
+		return nil, nil, errChangedFilesNotSet
+	}
+	return gh.files, nil, nil
+}
+
+func (gh *fakeGitHub) DiffTrees(ctx context.Context, owner, repo, base, head string) ([]*github.CommitFile, bool, error) {
+	if gh.files == nil {
+		return nil, false, errChangedFilesNotSet
+	}
+	return gh.files, false, nil
+}
+
+// GetMergeBase returns base because the history isn't available offline.
+func (gh *fakeGitHub) GetMergeBase(ctx context.Context, owner, repo, base, head string) (string, *github.Response, error) {
+	return base, nil, nil
+}
+
+func (gh *fakeGitHub) IsRateLimitLow() bool {
+	return false
+}
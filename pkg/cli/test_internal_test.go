@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_explain(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		wantErr      bool
+		cfg          *config.Config
+		evType       string
+		payload      string
+		changedFiles []string
+		exp          string
+	}{
+		{
+			name: "normal",
+			cfg: &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner: "gha-trigger",
+						RepoName:  "example-main",
+						Events: []*config.Event{
+							{
+								Workflow: &config.Workflow{
+									WorkflowFileName: "test.yaml",
+								},
+							},
+							{
+								Matches: []*config.Match{
+									{
+										Events: []*config.EventType{
+											{
+												Name: "push",
+											},
+										},
+									},
+								},
+								Workflow: &config.Workflow{
+									WorkflowFileName: "deploy.yaml",
+								},
+							},
+						},
+					},
+				},
+			},
+			evType:  "pull_request",
+			payload: `{"action": "opened", "repository": {"name": "example-main", "owner": {"login": "gha-trigger"}}}`,
+			exp: `event: pull_request (opened)
+repository: gha-trigger/example-main
+events[0] test.yaml: matched
+  no match condition
+events[1] deploy.yaml: not matched
+  matches[0]: fail
+    event_type: fail (input: pull_request:opened)
+`,
+		},
+		{
+			name:         "paths",
+			cfg:          pathsConfig(),
+			evType:       "push",
+			payload:      `{"ref": "refs/heads/main", "before": "aaa", "after": "bbb", "repository": {"name": "example-main", "owner": {"login": "gha-trigger"}}}`,
+			changedFiles: []string{"README.md", "docs/index.md"},
+			exp: `event: push
+repository: gha-trigger/example-main
+events[0] docs.yaml: matched
+  matches[0]: pass
+    event_type: pass (input: push)
+    branches: pass (input: main)
+    tags: pass
+    branches_ignore: pass (input: main)
+    tags_ignore: pass
+    paths: pass (input: [README.md, docs/index.md])
+    paths_ignore: pass (input: [README.md, docs/index.md])
+    if: pass
+`,
+		},
+		{
+			name:    "changed files aren't set",
+			wantErr: true,
+			cfg:     pathsConfig(),
+			evType:  "push",
+			payload: `{"ref": "refs/heads/main", "before": "aaa", "after": "bbb", "repository": {"name": "example-main", "owner": {"login": "gha-trigger"}}}`,
+		},
+		{
+			name:    "repository config isn't found",
+			cfg:     &config.Config{},
+			evType:  "push",
+			payload: `{"repository": {"name": "example-main", "owner": {"login": "gha-trigger"}}}`,
+			exp: `event: push
+repository: gha-trigger/example-main
+repository config isn't found
+`,
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ev, err := domain.ParseEvent(tt.evType, []byte(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev.GitHub = newFakeGitHub(tt.changedFiles)
+			buf := &bytes.Buffer{}
+			if err := explain(ctx, buf, tt.cfg, ev); err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(buf.String(), tt.exp); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func pathsConfig() *config.Config {
+	return &config.Config{
+		Repos: []*config.Repo{
+			{
+				RepoOwner: "gha-trigger",
+				RepoName:  "example-main",
+				Events: []*config.Event{
+					{
+						Matches: []*config.Match{
+							{
+								Events: []*config.EventType{
+									{
+										Name: "push",
+									},
+								},
+								Branches: []*config.StringMatch{
+									{
+										Type:  "equal",
+										Value: "main",
+									},
+								},
+								Paths: []*config.StringMatch{
+									{
+										Type:  "glob",
+										Value: "docs/*",
+									},
+								},
+							},
+						},
+						Workflow: &config.Workflow{
+							WorkflowFileName: "docs.yaml",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRunner_runTestCase(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		Repos: []*config.Repo{
+			{
+				RepoOwner: "gha-trigger",
+				RepoName:  "example-main",
+				Events: []*config.Event{
+					{
+						Workflow: &config.Workflow{
+							WorkflowFileName: "test.yaml",
+						},
+					},
+				},
+			},
+		},
+	}
+	exp := `event: push
+repository: gha-trigger/example-main
+events[0] test.yaml: matched
+  no match condition
+`
+	tests := []struct {
+		name      string
+		golden    string
+		update    bool
+		wantErr   error
+		expGolden string
+	}{
+		{
+			name:      "update",
+			golden:    "old\n",
+			update:    true,
+			expGolden: exp,
+		},
+		{
+			name:      "same",
+			golden:    exp,
+			expGolden: exp,
+		},
+		{
+			name:      "different",
+			golden:    "old\n",
+			wantErr:   errGoldenMismatch,
+			expGolden: "old\n",
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			files := map[string]string{
+				"push.json":   `{"repository": {"name": "example-main", "owner": {"login": "gha-trigger"}}}`,
+				"push.yaml":   "event: push\npayload: push.json\ngolden: push.golden\n",
+				"push.golden": tt.golden,
+			}
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil { //nolint:gosec
+					t.Fatal(err)
+				}
+			}
+			runner := &Runner{
+				Stdout: &bytes.Buffer{},
+				Stderr: &bytes.Buffer{},
+			}
+			err := runner.runTestCase(ctx, cfg, filepath.Join(dir, "push.yaml"), tt.update)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("wanted %v, got %v", tt.wantErr, err)
+			}
+			golden, err := os.ReadFile(filepath.Join(dir, "push.golden"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(golden), tt.expGolden); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRunner_runTestCase_fixtures(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"pull_request.json": `{"action": "opened", "repository": {"name": "example-main", "owner": {"login": "gha-trigger"}}}`,
+		// the pull request has more files than the list, so the changed files are got by comparing trees
+		"pr.json":           `{"number": 1, "changed_files": 3, "base": {"ref": "main", "sha": "aaa"}, "head": {"sha": "bbb"}}`,
+		"pull_request.yaml": "event: pull_request\npayload: pull_request.json\npull_request: pr.json\nchanged_files: [README.md, docs/index.md]\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil { //nolint:gosec
+			t.Fatal(err)
+		}
+	}
+	cfg := pathsConfig()
+	cfg.Repos[0].Events[0].Matches[0].Events[0].Name = "pull_request"
+	stdout := &bytes.Buffer{}
+	runner := &Runner{
+		Stdout: stdout,
+		Stderr: &bytes.Buffer{},
+	}
+	p := filepath.Join(dir, "pull_request.yaml")
+	if err := runner.runTestCase(context.Background(), cfg, p, false); err != nil {
+		t.Fatal(err)
+	}
+	exp := "# " + p + `
+event: pull_request (opened)
+repository: gha-trigger/example-main
+events[0] docs.yaml: matched
+  matches[0]: pass
+    event_type: pass (input: pull_request:opened)
+    branches: pass (input: main)
+    tags: pass
+    branches_ignore: pass (input: main)
+    tags_ignore: pass
+    paths: pass (input: [README.md, docs/index.md])
+    paths_ignore: pass (input: [README.md, docs/index.md])
+    if: pass
+`
+	if diff := cmp.Diff(exp, stdout.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
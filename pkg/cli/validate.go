@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+func (runner *Runner) newValidateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "Validate the configuration deeply",
+		Description: `Validate the configuration offline and output problems with their positions.
+
+In addition to the validation at startup, the following problems are found.
+
+- unknown keys
+- references to GitHub Apps which aren't defined
+- duplicate GitHub Apps, repositories, and events
+- invalid activity types
+- invalid regular expressions
+- unreachable matches (e.g. paths with events whose changed files can't be got)
+
+If --check-targets is set, the command also confirms with GitHub API that each GitHub App can access the repositories where workflows are run.
+This check reads GitHub Apps' secrets from AWS Secrets Manager, so it requires network access and AWS credentials.
+At runtime the same check only outputs warnings, so it's recommended to run it in CI.
+
+The command fails if any problem is found.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "config",
+				Aliases:  []string{"c"},
+				Usage:    "configuration file path",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "check-targets",
+				Usage: "confirm that GitHub Apps can access repositories where workflows are run (requires network access)",
+			},
+		},
+		Action: runner.validateAction,
+	}
+}
+
+var errConfigInvalid = errors.New("configuration is invalid")
+
+func (runner *Runner) validateAction(c *cli.Context) error {
+	p := c.String("config")
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("read a configuration file: %w", err)
+	}
+	diags := config.Check(b)
+	for _, diag := range diags {
+		fmt.Fprintf(runner.Stdout, "%s:%s\n", p, diag)
+	}
+	if len(diags) != 0 {
+		return errConfigInvalid
+	}
+	if !c.Bool("check-targets") {
+		return nil
+	}
+	cfg := &config.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return fmt.Errorf("parse the configuration as YAML: %w", err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		return fmt.Errorf("configuration is invalid: %w", err)
+	}
+	if err := config.Prepare(cfg); err != nil {
+		return err //nolint:wrapcheck
+	}
+	ghs, err := newGitHubClients(c.Context, cfg)
+	if err != nil {
+		return err
+	}
+	if !checkTargets(c.Context, runner.Stdout, p, cfg, ghs) {
+		return errConfigInvalid
+	}
+	return nil
+}
+
+func newGitHubClients(ctx context.Context, cfg *config.Config) (map[string]*github.Client, error) {
+	awsClient := aws.New(cfg.AWS)
+	ghs := make(map[string]*github.Client, len(cfg.GitHubApps))
+	for _, appCfg := range cfg.GitHubApps {
+		app, err := githubapp.New(ctx, awsClient, appCfg, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create a GitHub App client (name: %s): %w", appCfg.Name, err)
+		}
+		ghs[appCfg.Name] = app.Client
+	}
+	return ghs, nil
+}
+
+// checkTargets outputs repositories where workflows are run but GitHub Apps can't access.
+// It returns false if any problem is found.
+func checkTargets(ctx context.Context, w io.Writer, p string, cfg *config.Config, ghs map[string]*github.Client) bool {
+	ok := true
+	for _, repo := range cfg.Repos {
+		if err := config.ValidateWorkflowTargets(ctx, repo, ghs); err != nil {
+			fmt.Fprintf(w, "%s: %s\n", p, err)
+			ok = false
+		}
+	}
+	return ok
+}
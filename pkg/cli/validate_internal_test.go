@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_checkTargets(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/suzuki-shunsuke/foo-ci" {
+			w.Write([]byte(`{"name": "foo-ci"}`)) //nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	v3 := github.NewV3Client(srv.Client())
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3.BaseURL = baseURL
+	ghs := map[string]*github.Client{
+		"ci": github.New(v3),
+	}
+	tests := []struct {
+		name   string
+		ciRepo string
+		exp    string
+		expOK  bool
+	}{
+		{
+			name:   "reachable",
+			ciRepo: "foo-ci",
+			expOK:  true,
+		},
+		{
+			name:   "unreachable",
+			ciRepo: "bar-ci",
+			exp:    "gha-trigger.yaml: GitHub App can't access the repository (app: ci, repository: suzuki-shunsuke/bar-ci): the repository isn't found or the GitHub App isn't installed in it\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner:             "suzuki-shunsuke",
+						RepoName:              "foo",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            tt.ciRepo,
+						Events: []*config.Event{
+							{
+								Workflow: &config.Workflow{
+									WorkflowFileName: "test.yaml",
+								},
+							},
+						},
+					},
+				},
+			}
+			buf := &bytes.Buffer{}
+			ok := checkTargets(ctx, buf, "gha-trigger.yaml", cfg, ghs)
+			if ok != tt.expOK {
+				t.Fatalf("wanted %v, got %v", tt.expOK, ok)
+			}
+			if diff := cmp.Diff(tt.exp, buf.String()); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
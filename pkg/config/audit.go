@@ -0,0 +1,61 @@
+package config
+
+import "errors"
+
+// Audit is the setting of the audit log.
+// Every workflow dispatch and slash command action is recorded to the sink.
+type Audit struct {
+	// file, s3, or cloudwatch_logs
+	Type string
+	// Path is the path of the JSON Lines file
+	Path string
+	// Bucket and KeyPrefix are settings of Amazon S3. An object is created per record
+	Bucket    string
+	KeyPrefix string `yaml:"key_prefix"`
+	// LogGroupName and LogStreamName are settings of Amazon CloudWatch Logs.
+	// The log stream is created if it doesn't exist.
+	LogGroupName  string `yaml:"log_group_name"`
+	LogStreamName string `yaml:"log_stream_name"`
+}
+
+const (
+	AuditTypeFile           = "file"
+	AuditTypeS3             = "s3"
+	AuditTypeCloudWatchLogs = "cloudwatch_logs"
+)
+
+var (
+	errInvalidAuditType       = errors.New("audit type must be either file, s3, or cloudwatch_logs")
+	errAuditPathRequired      = errors.New("audit path is required")
+	errAuditBucketRequired    = errors.New("audit bucket is required")
+	errAuditLogGroupRequired  = errors.New("audit log_group_name is required")
+	errAuditLogStreamRequired = errors.New("audit log_stream_name is required")
+)
+
+func (a *Audit) Validate() error {
+	if a == nil {
+		return nil
+	}
+	switch a.Type {
+	case AuditTypeFile:
+		if a.Path == "" {
+			return errAuditPathRequired
+		}
+		return nil
+	case AuditTypeS3:
+		if a.Bucket == "" {
+			return errAuditBucketRequired
+		}
+		return nil
+	case AuditTypeCloudWatchLogs:
+		if a.LogGroupName == "" {
+			return errAuditLogGroupRequired
+		}
+		if a.LogStreamName == "" {
+			return errAuditLogStreamRequired
+		}
+		return nil
+	default:
+		return errInvalidAuditType
+	}
+}
@@ -0,0 +1,399 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a problem of the configuration found by Check.
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+type checker struct {
+	root  *yaml3.Node
+	diags []*Diagnostic
+}
+
+// Check validates the configuration deeply without accessing any external service.
+// In addition to Validate and Init, it checks unknown keys, references to GitHub Apps,
+// duplicate repositories and events, activity types, and unreachable matches.
+func Check(b []byte) []*Diagnostic {
+	doc := &yaml3.Node{}
+	if err := yaml3.Unmarshal(b, doc); err != nil {
+		return []*Diagnostic{errorToDiagnostic(err)}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	c := &checker{
+		root: doc.Content[0],
+	}
+	// the configuration is decoded by yaml.v2 like runtime, and yaml.v3 is used only to find positions
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		c.addDecodeError(err)
+		cfg = &Config{}
+		if err := yaml.Unmarshal(b, cfg); err != nil {
+			return c.sorted()
+		}
+	}
+	c.checkConfig(cfg)
+	return c.sorted()
+}
+
+func (c *checker) sorted() []*Diagnostic {
+	sort.SliceStable(c.diags, func(i, j int) bool {
+		if c.diags[i].Line != c.diags[j].Line {
+			return c.diags[i].Line < c.diags[j].Line
+		}
+		return c.diags[i].Column < c.diags[j].Column
+	})
+	return c.diags
+}
+
+var (
+	errorLinePattern     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	fieldNotFoundPattern = regexp.MustCompile(`^field (\S+) not found in type`)
+)
+
+func errorToDiagnostic(err error) *Diagnostic {
+	return messageToDiagnostic(err.Error())
+}
+
+func messageToDiagnostic(msg string) *Diagnostic {
+	m := errorLinePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return &Diagnostic{
+			Message: msg,
+		}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &Diagnostic{
+		Line:    line,
+		Message: m[2],
+	}
+}
+
+func (c *checker) addDecodeError(err error) {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		c.diags = append(c.diags, errorToDiagnostic(err))
+		return
+	}
+	for _, msg := range typeErr.Errors {
+		diag := messageToDiagnostic(msg)
+		if m := fieldNotFoundPattern.FindStringSubmatch(diag.Message); m != nil {
+			if node := findKey(c.root, diag.Line, m[1]); node != nil {
+				diag.Column = node.Column
+			}
+			diag.Message = "unknown key " + m[1]
+		}
+		c.diags = append(c.diags, diag)
+	}
+}
+
+func findKey(node *yaml3.Node, line int, key string) *yaml3.Node {
+	if node.Kind == yaml3.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if k := node.Content[i]; k.Line == line && k.Value == key {
+				return k
+			}
+		}
+	}
+	for _, child := range node.Content {
+		if n := findKey(child, line, key); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// lookup returns the node of the path.
+// If the path isn't found, the deepest found node is returned.
+func lookup(node *yaml3.Node, path ...interface{}) *yaml3.Node {
+	for _, p := range path {
+		next := child(node, p)
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+func child(node *yaml3.Node, p interface{}) *yaml3.Node {
+	switch k := p.(type) {
+	case string:
+		if node.Kind != yaml3.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == k {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		if node.Kind == yaml3.SequenceNode && k < len(node.Content) {
+			return node.Content[k]
+		}
+	}
+	return nil
+}
+
+func (c *checker) add(path []interface{}, format string, args ...interface{}) {
+	node := lookup(c.root, path...)
+	c.diags = append(c.diags, &Diagnostic{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func appendPath(path []interface{}, elems ...interface{}) []interface{} {
+	p := make([]interface{}, 0, len(path)+len(elems))
+	p = append(p, path...)
+	return append(p, elems...)
+}
+
+func (c *checker) validateStruct(path []interface{}, v interface{}) {
+	err := Validate(v)
+	if err == nil {
+		return
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		c.add(path, "%s", err.Error())
+		return
+	}
+	for _, fe := range verrs {
+		// Namespace is like "Repo.repo_owner"
+		names := strings.Split(fe.Namespace(), ".")[1:]
+		p := path
+		for _, name := range names {
+			p = appendPath(p, name)
+		}
+		if fe.Param() != "" {
+			c.add(p, "%s is invalid: %s=%s", strings.Join(names, "."), fe.Tag(), fe.Param())
+			continue
+		}
+		c.add(p, "%s is invalid: %s", strings.Join(names, "."), fe.Tag())
+	}
+}
+
+func (c *checker) checkConfig(cfg *Config) {
+	if err := cfg.MergeablePolling.Validate(); err != nil {
+		c.add([]interface{}{"mergeable_polling"}, "%s", err.Error())
+	}
+	if err := cfg.Queue.Validate(); err != nil {
+		c.add([]interface{}{"queue"}, "%s", err.Error())
+	}
+	if err := cfg.Audit.Validate(); err != nil {
+		c.add([]interface{}{"audit"}, "%s", err.Error())
+	}
+
+	apps := make(map[string]struct{}, len(cfg.GitHubApps))
+	for i, app := range cfg.GitHubApps {
+		path := []interface{}{"github_apps", i}
+		c.validateStruct(path, app)
+		if _, ok := apps[app.Name]; ok {
+			c.add(appendPath(path, "name"), "GitHub App name is duplicated: %s", app.Name)
+		}
+		apps[app.Name] = struct{}{}
+	}
+
+	for i, org := range cfg.Orgs {
+		path := []interface{}{"orgs", i}
+		c.validateStruct(path, org)
+		if _, ok := apps[org.WorkflowGitHubAppName]; !ok && org.WorkflowGitHubAppName != "" {
+			c.add(appendPath(path, "workflow_github_app_name"), "GitHub App isn't found: %s", org.WorkflowGitHubAppName)
+		}
+		for j, ev := range org.Events {
+			p := appendPath(path, "events", j)
+			c.validateStruct(p, ev)
+			c.checkEvent(p, ev)
+		}
+	}
+
+	repos := make(map[string]struct{}, len(cfg.Repos))
+	for i, repo := range cfg.Repos {
+		path := []interface{}{"repos", i}
+		c.validateStruct(path, repo)
+		if !repo.IsPattern() {
+			key := repo.RepoOwner + "/" + repo.RepoName
+			if _, ok := repos[key]; ok {
+				c.add(path, "repository is duplicated: %s", key)
+			}
+			repos[key] = struct{}{}
+		}
+		if _, ok := apps[repo.WorkflowGitHubAppName]; !ok && repo.WorkflowGitHubAppName != "" {
+			c.add(appendPath(path, "workflow_github_app_name"), "GitHub App isn't found: %s", repo.WorkflowGitHubAppName)
+		}
+		r := *repo
+		if _, err := applyOrgs(&r, cfg.Orgs); err != nil {
+			c.add(path, "%s", err.Error())
+		}
+		if repo.RepoConfig != nil {
+			for j, name := range repo.RepoConfig.AllowedGitHubApps {
+				if _, ok := apps[name]; !ok {
+					c.add(appendPath(path, "repo_config", "allowed_github_apps", j), "GitHub App isn't found: %s", name)
+				}
+			}
+		}
+		c.checkRepo(path, repo, apps)
+	}
+}
+
+func (c *checker) checkRepo(path []interface{}, repo *Repo, apps map[string]struct{}) {
+	if err := validateChangedFilesUnknown(repo.ChangedFilesUnknown); err != nil {
+		c.add(appendPath(path, "changed_files_unknown"), "%s", err.Error())
+	}
+	if err := repo.MergeablePolling.Validate(); err != nil {
+		c.add(appendPath(path, "mergeable_polling"), "%s", err.Error())
+	}
+	for i, schedule := range repo.Schedules {
+		p := appendPath(path, "schedules", i)
+		c.validateStruct(p, schedule)
+		if err := schedule.Compile(); err != nil {
+			c.add(appendPath(p, "cron"), "%s", err.Error())
+		}
+	}
+	events := map[string]int{}
+	for i, ev := range repo.Events {
+		p := appendPath(path, "events", i)
+		c.validateStruct(p, ev)
+		c.checkEvent(p, ev)
+		if err := ev.Workflow.compileRef(); err != nil {
+			c.add(appendPath(p, "workflow", "ref"), "%s", err.Error())
+		}
+		if ev.Workflow != nil && ev.Workflow.GitHubAppName != "" {
+			if _, ok := apps[ev.Workflow.GitHubAppName]; !ok {
+				c.add(appendPath(p, "workflow", "github_app_name"), "GitHub App isn't found: %s", ev.Workflow.GitHubAppName)
+			}
+		}
+		key, err := eventKey(ev)
+		if err != nil {
+			continue
+		}
+		if j, ok := events[key]; ok {
+			c.add(p, "event is duplicated with events[%d]", j)
+			continue
+		}
+		events[key] = i
+	}
+}
+
+func eventKey(ev *Event) (string, error) {
+	b, err := yaml3.Marshal(ev)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	return string(b), nil
+}
+
+func (c *checker) checkEvent(path []interface{}, ev *Event) {
+	if ev.On != nil {
+		for _, e := range ev.On.Events {
+			p := appendPath(path, "on", e.Name)
+			evType := &EventType{
+				Name:  e.Name,
+				Types: e.Types,
+			}
+			c.checkEventType(p, evType)
+			if _, err := e.compile(); err != nil {
+				c.add(p, "%s", err.Error())
+			}
+		}
+	}
+	if len(ev.Matches) == 0 {
+		return
+	}
+	reachable := false
+	for i, match := range ev.Matches {
+		p := appendPath(path, "matches", i)
+		if c.checkMatch(p, match) {
+			reachable = true
+		}
+	}
+	if !reachable && ev.On == nil {
+		c.add(path, "event is unreachable because all matches are unreachable")
+	}
+}
+
+func (c *checker) checkEventType(path []interface{}, evType *EventType) {
+	if err := Validate(evType); err != nil {
+		c.add(appendPath(path, "name"), "event name is invalid: %s", evType.Name)
+		return
+	}
+	for i, typ := range evType.Types {
+		if !validActivityType(evType.Name, typ) {
+			c.add(appendPath(path, "types", i), "activity type %s is invalid for the event %s", typ, evType.Name)
+		}
+	}
+}
+
+// checkMatch returns false if the match is unreachable.
+func (c *checker) checkMatch(path []interface{}, match *Match) bool {
+	for i, evType := range match.Events {
+		c.checkEventType(appendPath(path, "events", i), evType)
+	}
+	if err := validateChangedFilesUnknown(match.ChangedFilesUnknown); err != nil {
+		c.add(appendPath(path, "changed_files_unknown"), "%s", err.Error())
+	}
+	fields := []struct {
+		key  string
+		sms  []*StringMatch
+		evts map[string]struct{}
+	}{
+		{"branches", match.Branches, refEvents},
+		{"branches-ignore", match.BranchesIgnore, nil},
+		{"tags", match.Tags, tagEvents},
+		{"tags-ignore", match.TagsIgnore, nil},
+		{"paths", match.Paths, pathEvents},
+		{"paths-ignore", match.PathsIgnore, nil},
+	}
+	reachable := true
+	for _, field := range fields {
+		for i, sm := range field.sms {
+			p := appendPath(path, field.key, i)
+			if err := sm.Validate(); err != nil {
+				c.add(appendPath(p, "type"), "string match type is invalid: %s", sm.Type)
+				continue
+			}
+			if err := sm.Compile(); err != nil {
+				c.add(appendPath(p, "value"), "regular expression is invalid: %s", err.Error())
+			}
+		}
+		if len(field.sms) == 0 || field.evts == nil || len(match.Events) == 0 {
+			continue
+		}
+		if !anyEvent(match.Events, field.evts) {
+			c.add(appendPath(path, field.key), "match is unreachable because no event has %s", field.key)
+			reachable = false
+		}
+	}
+	return reachable
+}
+
+func anyEvent(evTypes []*EventType, events map[string]struct{}) bool {
+	for _, evType := range evTypes {
+		if _, ok := events[evType.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
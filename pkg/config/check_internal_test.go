@@ -0,0 +1,133 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		yaml string
+		exp  []string
+	}{
+		{
+			name: "valid",
+			yaml: `
+github_apps:
+  - name: ci
+    secret:
+      type: aws_secretsmanager
+      secret_id: foo
+repos:
+  - repo_owner: suzuki-shunsuke
+    repo_name: foo
+    workflow_github_app_name: ci
+    ci_repo_name: foo-ci
+    events:
+      - matches:
+          - events:
+              - name: pull_request
+                types: [opened]
+            paths:
+              - type: glob
+                value: "*.go"
+        workflow:
+          workflow_file_name: test.yaml
+          ref: main
+`,
+		},
+		{
+			name: "org defaults for owner patterns",
+			yaml: `
+github_apps:
+  - name: ci
+    secret:
+      type: aws_secretsmanager
+      secret_id: foo
+orgs:
+  - name: suzuki-shunsuke
+    workflow_github_app_name: ci
+    ci_repo_name: ci
+repos:
+  - repo_owner_match:
+      type: prefix
+      value: suzuki-
+    repo_name: foo
+    events:
+      - workflow:
+          workflow_file_name: test.yaml
+`,
+		},
+		{
+			name: "syntax error",
+			yaml: "repos: [",
+			exp: []string{
+				"1:0: did not find expected node content",
+			},
+		},
+		{
+			name: "problems",
+			yaml: `
+github_apps:
+  - name: ci
+    unknown: foo
+    secret:
+      type: aws_secretsmanager
+      secret_id: foo
+repos:
+  - repo_owner: suzuki-shunsuke
+    repo_name: foo
+    workflow_github_app_name: cd
+    ci_repo_name: foo-ci
+    events:
+      - matches:
+          - events:
+              - name: push
+                types: [opened]
+            paths:
+              - type: regexp
+                value: "("
+          - events:
+              - name: issues
+            paths:
+              - type: glob
+                value: "*.go"
+        workflow:
+          workflow_file_name: test.yaml
+          ref: main
+      - workflow:
+          ref: main
+  - repo_owner: suzuki-shunsuke
+    repo_name: foo
+    workflow_github_app_name: ci
+    ci_repo_name: foo-ci
+`,
+			exp: []string{
+				"4:5: unknown key unknown",
+				"11:31: GitHub App isn't found: cd",
+				"17:25: activity type opened is invalid for the event push",
+				"20:24: regular expression is invalid: error parsing regexp: missing closing ): `(`",
+				"24:15: match is unreachable because no event has paths",
+				"30:11: workflow.workflow_file_name is invalid: required",
+				"31:5: repository is duplicated: suzuki-shunsuke/foo",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			diags := Check([]byte(tt.yaml))
+			var msgs []string
+			for _, diag := range diags {
+				msgs = append(msgs, diag.String())
+			}
+			if diff := cmp.Diff(tt.exp, msgs); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+// activityTypes is the list of the webhook payload's action of each event.
+// Events which aren't in the map don't have activity types or allow any types (e.g. repository_dispatch).
+// https://docs.github.com/en/webhooks-and-events/webhooks/webhook-events-and-payloads
+var activityTypes = map[string][]string{ //nolint:gochecknoglobals
+	"branch_protection_rule":      {"created", "edited", "deleted"},
+	"check_run":                   {"created", "rerequested", "completed", "requested_action"},
+	"check_suite":                 {"completed", "requested", "rerequested"},
+	"deployment_status":           {"created"},
+	"discussion":                  {"created", "edited", "deleted", "transferred", "pinned", "unpinned", "labeled", "unlabeled", "locked", "unlocked", "category_changed", "answered", "unanswered"},
+	"discussion_comment":          {"created", "edited", "deleted"},
+	"issue_comment":               {"created", "edited", "deleted"},
+	"issues":                      {"opened", "edited", "deleted", "transferred", "pinned", "unpinned", "closed", "reopened", "assigned", "unassigned", "labeled", "unlabeled", "locked", "unlocked", "milestoned", "demilestoned"},
+	"label":                       {"created", "edited", "deleted"},
+	"merge_group":                 {"checks_requested", "destroyed"},
+	"milestone":                   {"created", "closed", "opened", "edited", "deleted"},
+	"project":                     {"created", "closed", "reopened", "edited", "deleted"},
+	"project_card":                {"created", "moved", "converted", "edited", "deleted"},
+	"project_column":              {"created", "updated", "moved", "deleted"},
+	"pull_request":                pullRequestActivityTypes,
+	"pull_request_target":         pullRequestActivityTypes,
+	"pull_request_review":         {"submitted", "edited", "dismissed"},
+	"pull_request_review_comment": {"created", "edited", "deleted"},
+	"registry_package":            {"published", "updated"},
+	"release":                     {"published", "unpublished", "created", "edited", "deleted", "prereleased", "released"},
+	"watch":                       {"started"},
+	"workflow_run":                {"completed", "requested", "in_progress"},
+}
+
+var pullRequestActivityTypes = []string{ //nolint:gochecknoglobals
+	"assigned", "unassigned", "labeled", "unlabeled", "opened", "edited", "closed", "reopened", "synchronize",
+	"converted_to_draft", "ready_for_review", "locked", "unlocked", "review_requested", "review_request_removed",
+	"auto_merge_enabled", "auto_merge_disabled", "enqueued", "dequeued", "milestoned", "demilestoned",
+}
+
+// typelessEvents are events which have no activity type.
+var typelessEvents = map[string]struct{}{ //nolint:gochecknoglobals
+	"create":     {},
+	"delete":     {},
+	"fork":       {},
+	"gollum":     {},
+	"page_build": {},
+	"public":     {},
+	"push":       {},
+	"schedule":   {},
+	"status":     {},
+}
+
+// refEvents are events whose payload has a branch.
+var refEvents = map[string]struct{}{ //nolint:gochecknoglobals
+	"create":                      {},
+	"delete":                      {},
+	"merge_group":                 {},
+	"pull_request":                {},
+	"pull_request_review":         {},
+	"pull_request_review_comment": {},
+	"pull_request_target":         {},
+	"push":                        {},
+}
+
+// tagEvents are events whose payload has a tag.
+var tagEvents = map[string]struct{}{ //nolint:gochecknoglobals
+	"create": {},
+	"delete": {},
+	"push":   {},
+}
+
+// pathEvents are events whose changed files can be got.
+var pathEvents = map[string]struct{}{ //nolint:gochecknoglobals
+	"merge_group":         {},
+	"pull_request":        {},
+	"pull_request_target": {},
+	"push":                {},
+}
+
+func validActivityType(evName, typ string) bool {
+	if _, ok := typelessEvents[evName]; ok {
+		return false
+	}
+	types, ok := activityTypes[evName]
+	if !ok {
+		return true
+	}
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
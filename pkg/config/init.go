@@ -1,22 +1,96 @@
 package config
 
+import "fmt"
+
 func Init(cfg *Config) error {
+	if err := cfg.MergeablePolling.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Queue.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Audit.Validate(); err != nil {
+		return err
+	}
 	for _, repo := range cfg.Repos {
-		for _, event := range repo.Events {
-			for _, match := range event.Matches {
-				if err := match.Compile(); err != nil {
-					return err
-				}
-				for _, ev := range match.Events {
-					if ev.Name == "pull_request" && ev.Types == nil {
-						// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#pull_request
-						// > By default, a workflow only runs when a pull_request event's activity type is
-						// > opened, synchronize, or reopened.
-						ev.Types = []string{"opened", "synchronize", "reopened"}
-					}
-				}
+		if err := repo.MergeablePolling.Validate(); err != nil {
+			return err
+		}
+		if repo.MergeablePolling == nil {
+			repo.MergeablePolling = cfg.MergeablePolling
+		}
+		if cfg.DryRun {
+			repo.DryRun = true
+		}
+		for _, schedule := range repo.Schedules {
+			if err := schedule.Compile(); err != nil {
+				return err
+			}
+			if err := schedule.Workflow.compileRef(); err != nil {
+				return err
 			}
 		}
+		if err := InitEvents(repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitEvents compiles and sets defaults to the repository's events.
+func InitEvents(repo *Repo) error {
+	if err := validateChangedFilesUnknown(repo.ChangedFilesUnknown); err != nil {
+		return err
+	}
+	changedFilesUnknown := repo.getChangedFilesUnknown()
+	for _, event := range repo.Events {
+		if event.NeedsWorkflowOn() {
+			// the event is initialized by LoadWorkflowOn at the first event of the repository
+			continue
+		}
+		if err := initEvent(event, changedFilesUnknown); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+func initEvent(event *Event, changedFilesUnknown string) error {
+	if err := event.Workflow.compileRef(); err != nil {
+		return err
+	}
+	if event.On != nil {
+		matches, err := event.On.Compile()
+		if err != nil {
+			return fmt.Errorf("compile on: %w", err)
+		}
+		event.Matches = append(event.Matches, matches...)
+	}
+	for _, match := range event.Matches {
+		if err := match.Compile(); err != nil {
+			return err
+		}
+		if err := validateChangedFilesUnknown(match.ChangedFilesUnknown); err != nil {
+			return err
+		}
+		if match.ChangedFilesUnknown == "" {
+			match.ChangedFilesUnknown = changedFilesUnknown
+		}
+		for _, ev := range match.Events {
+			if ev.Name == "pull_request" && ev.Types == nil {
+				// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#pull_request
+				// > By default, a workflow only runs when a pull_request event's activity type is
+				// > opened, synchronize, or reopened.
+				ev.Types = []string{"opened", "synchronize", "reopened"}
+			}
+		}
+	}
+	return nil
+}
+
+func (repo *Repo) getChangedFilesUnknown() string {
+	if repo.ChangedFilesUnknown == "" {
+		return ChangedFilesUnknownFailOpen
+	}
+	return repo.ChangedFilesUnknown
+}
@@ -67,6 +67,7 @@ func TestInit(t *testing.T) {
 												Name: "push",
 											},
 										},
+										ChangedFilesUnknown: "fail_open",
 									},
 									{
 										Branches: []*StringMatch{
@@ -80,6 +81,7 @@ func TestInit(t *testing.T) {
 												Value: "main",
 											},
 										},
+										ChangedFilesUnknown: "fail_open",
 									},
 								},
 							},
@@ -103,7 +105,7 @@ func TestInit(t *testing.T) {
 			if tt.wantErr {
 				t.Fatal("error must be returned")
 			}
-			opt := cmp.AllowUnexported(StringMatch{}, regexp.Regexp{})
+			opt := cmp.AllowUnexported(StringMatch{}, Repo{}, Workflow{}, regexp.Regexp{})
 			if diff := cmp.Diff(tt.cfg, tt.exp, opt); diff != "" {
 				t.Fatal(diff)
 			}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// MergeablePolling is the setting to wait until a pull request's mergeable is computed.
+// https://docs.github.com/en/rest/guides/getting-started-with-the-git-database-api#checking-mergeability-of-pull-requests
+type MergeablePolling struct {
+	// Interval is the first polling interval. The interval is doubled up to MaxInterval
+	Interval    time.Duration
+	MaxInterval time.Duration `yaml:"max_interval"`
+	MaxWait     time.Duration `yaml:"max_wait"`
+	// OnTimeout is the policy when mergeable isn't computed in MaxWait.
+	// fail (default): return an error
+	// skip: workflows aren't run
+	// head: workflows are run against the pull request's head commit
+	OnTimeout string `yaml:"on_timeout"`
+}
+
+const (
+	OnTimeoutFail = "fail"
+	OnTimeoutSkip = "skip"
+	OnTimeoutHead = "head"
+
+	defaultMergeablePollingInterval    = time.Second
+	defaultMergeablePollingMaxInterval = 10 * time.Second
+	defaultMergeablePollingMaxWait     = 100 * time.Second
+)
+
+var errInvalidOnTimeout = errors.New("on_timeout must be one of fail, skip, and head")
+
+func (p *MergeablePolling) Validate() error {
+	if p == nil {
+		return nil
+	}
+	switch p.OnTimeout {
+	case "", OnTimeoutFail, OnTimeoutSkip, OnTimeoutHead:
+		return nil
+	default:
+		return errInvalidOnTimeout
+	}
+}
+
+func (p *MergeablePolling) GetInterval() time.Duration {
+	if p == nil || p.Interval <= 0 {
+		return defaultMergeablePollingInterval
+	}
+	return p.Interval
+}
+
+func (p *MergeablePolling) GetMaxInterval() time.Duration {
+	if p == nil || p.MaxInterval <= 0 {
+		return defaultMergeablePollingMaxInterval
+	}
+	return p.MaxInterval
+}
+
+func (p *MergeablePolling) GetMaxWait() time.Duration {
+	if p == nil || p.MaxWait <= 0 {
+		return defaultMergeablePollingMaxWait
+	}
+	return p.MaxWait
+}
+
+func (p *MergeablePolling) GetOnTimeout() string {
+	if p == nil || p.OnTimeout == "" {
+		return OnTimeoutFail
+	}
+	return p.OnTimeout
+}
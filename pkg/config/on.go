@@ -0,0 +1,303 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"gopkg.in/yaml.v2"
+)
+
+// On is GitHub Actions' `on` syntax.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#on
+type On struct {
+	Events []*OnEvent
+}
+
+type OnEvent struct {
+	Name           string
+	Types          []string
+	Branches       []string
+	BranchesIgnore []string `yaml:"branches-ignore"`
+	Tags           []string
+	TagsIgnore     []string `yaml:"tags-ignore"`
+	Paths          []string
+	PathsIgnore    []string `yaml:"paths-ignore"`
+}
+
+var (
+	errInvalidOn = errors.New("on must be a string, a list of strings, or a map")
+	// errEmptyOn is returned because an event without Matches matches every webhook.
+	errEmptyOn = errors.New("on must have at least one event")
+)
+
+// UnmarshalYAML accepts all forms of `on`.
+//
+//	on: push
+//	on: [push, pull_request]
+//	on:
+//	  push:
+//	    branches: [main]
+func (on *On) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		on.Events = []*OnEvent{{Name: name}}
+		return nil
+	}
+	var names []string
+	if err := unmarshal(&names); err == nil {
+		events := make([]*OnEvent, len(names))
+		for i, name := range names {
+			events[i] = &OnEvent{Name: name}
+		}
+		on.Events = events
+		return nil
+	}
+	m := map[string]*OnEvent{}
+	if err := unmarshal(&m); err != nil {
+		return errInvalidOn
+	}
+	names = make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	events := make([]*OnEvent, len(names))
+	for i, name := range names {
+		ev := m[name]
+		if ev == nil {
+			// on:
+			//   push:
+			ev = &OnEvent{}
+		}
+		ev.Name = name
+		events[i] = ev
+	}
+	on.Events = events
+	return nil
+}
+
+// Compile converts `on` to Matches.
+// Each event becomes a Match, so events are OR condition and filters of an event are AND condition like GitHub Actions.
+func (on *On) Compile() ([]*Match, error) {
+	if len(on.Events) == 0 {
+		return nil, errEmptyOn
+	}
+	matches := make([]*Match, 0, len(on.Events))
+	for _, ev := range on.Events {
+		match, err := ev.compile()
+		if err != nil {
+			return nil, fmt.Errorf("compile the event %s: %w", ev.Name, err)
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+func (ev *OnEvent) compile() (*Match, error) {
+	evType := &EventType{
+		Name:  ev.Name,
+		Types: ev.Types,
+	}
+	if err := Validate(evType); err != nil {
+		return nil, err
+	}
+	match := &Match{
+		Events: []*EventType{evType},
+	}
+	fields := []struct {
+		patterns []string
+		dest     *[]*StringMatch
+	}{
+		{ev.Branches, &match.Branches},
+		{ev.BranchesIgnore, &match.BranchesIgnore},
+		{ev.Tags, &match.Tags},
+		{ev.TagsIgnore, &match.TagsIgnore},
+		{ev.Paths, &match.Paths},
+		{ev.PathsIgnore, &match.PathsIgnore},
+	}
+	for _, field := range fields {
+		sms, err := compileFilterPatterns(field.patterns)
+		if err != nil {
+			return nil, err
+		}
+		*field.dest = sms
+	}
+	return match, nil
+}
+
+func compileFilterPatterns(patterns []string) ([]*StringMatch, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	sms := make([]*StringMatch, len(patterns))
+	for i, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			return nil, fmt.Errorf("negative pattern isn't supported, please use *-ignore instead: %s", pattern)
+		}
+		sms[i] = &StringMatch{
+			Type:  "regexp",
+			Value: filterPatternToRegexp(pattern),
+		}
+	}
+	return sms, nil
+}
+
+// filterPatternToRegexp converts GitHub Actions' filter pattern to a regular expression.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet
+// `?` and `+` are quantifiers only if they follow a character or a character set.
+// Otherwise they're matched literally, e.g. `*?` and `+foo`, so the regular expression is always valid.
+func filterPatternToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	runes := []rune(pattern)
+	// quantifiable is true if the last element is a character or a character set
+	quantifiable := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		prev := quantifiable
+		quantifiable = false
+		switch r {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					// `**/` also matches the root directory
+					i++
+					b.WriteString("(?:.*/)?")
+					continue
+				}
+				b.WriteString(".*")
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?', '+':
+			if prev {
+				// zero or one / one or more of the preceding character
+				b.WriteRune(r)
+				continue
+			}
+			b.WriteString(regexpQuoteRune(r))
+			quantifiable = true
+		case '[':
+			j := i + 1
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			if j == len(runes) {
+				b.WriteString(`\[`)
+				quantifiable = true
+				continue
+			}
+			b.WriteString(string(runes[i : j+1]))
+			i = j
+			quantifiable = true
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexpQuoteRune(runes[i]))
+				quantifiable = true
+				continue
+			}
+			b.WriteString(`\\`)
+			quantifiable = true
+		default:
+			b.WriteString(regexpQuoteRune(r))
+			quantifiable = true
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func regexpQuoteRune(r rune) string {
+	if strings.ContainsRune(`.^$|(){}[]*+?\`, r) {
+		return `\` + string(r)
+	}
+	return string(r)
+}
+
+type FileContentGetter interface {
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, *github.Response, error)
+}
+
+type workflowFile struct {
+	On *On `yaml:"on"`
+}
+
+// ReadWorkflowOn reads `on` from the workflow file in the CI repository and sets it to the event.
+// workflow_dispatch and workflow_call are ignored because gha-trigger runs the workflow by workflow_dispatch.
+// If no other event remains, an error is returned so that the workflow isn't run by every webhook.
+// If the ref is a template, the file is read from ref_fallback or the default branch.
+func ReadWorkflowOn(ctx context.Context, gh FileContentGetter, owner, repo string, ev *Event) error {
+	wf := ev.Workflow
+	ref := wf.Ref
+	if wf.IsRefTemplate() {
+		// the ref is rendered per event, so `on` is read from ref_fallback or the default branch
+		ref = wf.RefFallback
+	}
+	content, _, err := gh.GetFileContent(ctx, owner, repo, ".github/workflows/"+wf.WorkflowFileName, ref)
+	if err != nil {
+		return fmt.Errorf("get a workflow file %s: %w", wf.WorkflowFileName, err)
+	}
+	file := &workflowFile{}
+	if err := yaml.Unmarshal([]byte(content), file); err != nil {
+		return fmt.Errorf("parse a workflow file %s as YAML: %w", wf.WorkflowFileName, err)
+	}
+	if file.On == nil {
+		return fmt.Errorf("on isn't found in a workflow file %s", wf.WorkflowFileName)
+	}
+	events := make([]*OnEvent, 0, len(file.On.Events))
+	for _, e := range file.On.Events {
+		if e.Name == "workflow_dispatch" || e.Name == "workflow_call" {
+			continue
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("a workflow file %s has no event other than workflow_dispatch and workflow_call", wf.WorkflowFileName)
+	}
+	ev.On = &On{
+		Events: events,
+	}
+	return nil
+}
+
+// NeedsWorkflowOn returns true if `on` must be read from the workflow file before the event is routed.
+func (ev *Event) NeedsWorkflowOn() bool {
+	return ev.OnFromWorkflow && ev.On == nil
+}
+
+// LoadWorkflowOn reads `on` of events with on_from_workflow from the workflow files and initializes the events.
+// It's called at the first event of the repository instead of startup so that GitHub API isn't called at startup.
+// repo isn't changed and a copy is returned. If no event needs `on`, repo itself is returned.
+func LoadWorkflowOn(ctx context.Context, repo *Repo, ghs map[string]*github.Client) (*Repo, error) {
+	needed := false
+	for _, ev := range repo.Events {
+		if ev.NeedsWorkflowOn() {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return repo, nil
+	}
+	r := *repo
+	r.Events = copyEvents(repo.Events)
+	for _, ev := range r.Events {
+		if !ev.NeedsWorkflowOn() {
+			continue
+		}
+		wf := ev.Workflow
+		if err := ReadWorkflowOn(ctx, ghs[wf.GetGitHubAppName(&r)], wf.GetRepoOwner(&r), wf.GetRepoName(&r), ev); err != nil {
+			return nil, fmt.Errorf("read on from the workflow file: %w", err)
+		}
+		if err := initEvent(ev, r.getChangedFilesUnknown()); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
@@ -0,0 +1,389 @@
+package config
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+)
+
+func TestOn_UnmarshalYAML(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		wantErr bool
+		yaml    string
+		exp     *On
+	}{
+		{
+			name: "string",
+			yaml: "push",
+			exp: &On{
+				Events: []*OnEvent{
+					{
+						Name: "push",
+					},
+				},
+			},
+		},
+		{
+			name: "list",
+			yaml: "[push, pull_request]",
+			exp: &On{
+				Events: []*OnEvent{
+					{
+						Name: "push",
+					},
+					{
+						Name: "pull_request",
+					},
+				},
+			},
+		},
+		{
+			name: "map",
+			yaml: `
+push:
+  branches: [main]
+  paths-ignore: ["docs/**"]
+pull_request:
+`,
+			exp: &On{
+				Events: []*OnEvent{
+					{
+						Name: "pull_request",
+					},
+					{
+						Name:        "push",
+						Branches:    []string{"main"},
+						PathsIgnore: []string{"docs/**"},
+					},
+				},
+			},
+		},
+		{
+			name:    "invalid",
+			yaml:    "[{push: foo}]",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			on := &On{}
+			if err := yaml.Unmarshal([]byte(tt.yaml), on); err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(on, tt.exp); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestOn_Compile(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		wantErr bool
+		on      *On
+		exp     []*Match
+	}{
+		{
+			name: "normal",
+			on: &On{
+				Events: []*OnEvent{
+					{
+						Name:  "pull_request",
+						Types: []string{"opened"},
+						Paths: []string{"**/*.go"},
+					},
+					{
+						Name:     "push",
+						Branches: []string{"main", "release/*"},
+					},
+				},
+			},
+			exp: []*Match{
+				{
+					Events: []*EventType{
+						{
+							Name:  "pull_request",
+							Types: []string{"opened"},
+						},
+					},
+					Paths: []*StringMatch{
+						{
+							Type:  "regexp",
+							Value: `^(?:.*/)?[^/]*\.go$`,
+						},
+					},
+				},
+				{
+					Events: []*EventType{
+						{
+							Name: "push",
+						},
+					},
+					Branches: []*StringMatch{
+						{
+							Type:  "regexp",
+							Value: "^main$",
+						},
+						{
+							Type:  "regexp",
+							Value: "^release/[^/]*$",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown event",
+			on: &On{
+				Events: []*OnEvent{
+					{
+						Name: "foo",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			on:      &On{},
+			wantErr: true,
+		},
+		{
+			name: "negative pattern",
+			on: &On{
+				Events: []*OnEvent{
+					{
+						Name:     "push",
+						Branches: []string{"!main"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			matches, err := tt.on.Compile()
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			opt := cmp.AllowUnexported(StringMatch{})
+			if diff := cmp.Diff(matches, tt.exp, opt); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+type fileContentGetter struct {
+	content string
+	ref     string
+}
+
+func (gh *fileContentGetter) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, *github.Response, error) {
+	gh.ref = ref
+	return gh.content, nil, nil
+}
+
+func TestReadWorkflowOn(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		exp     *On
+		wf      *Workflow
+		expRef  string
+	}{
+		{
+			name: "workflow_dispatch is ignored",
+			content: `on:
+  workflow_dispatch:
+  push:
+    branches: [main]
+`,
+			exp: &On{
+				Events: []*OnEvent{
+					{
+						Name:     "push",
+						Branches: []string{"main"},
+					},
+				},
+			},
+		},
+		{
+			name:    "ref is a template",
+			content: "on: push\n",
+			wf: &Workflow{
+				WorkflowFileName: "test.yaml",
+				Ref:              "{{ .BaseRef }}",
+				RefFallback:      "main",
+			},
+			exp: &On{
+				Events: []*OnEvent{
+					{
+						Name: "push",
+					},
+				},
+			},
+			expRef: "main",
+		},
+		{
+			name: "dispatch only",
+			content: `on:
+  workflow_dispatch:
+  workflow_call:
+`,
+			wantErr: true,
+		},
+		{
+			name:    "on isn't found",
+			content: "name: test\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			wf := tt.wf
+			if wf == nil {
+				wf = &Workflow{
+					WorkflowFileName: "test.yaml",
+				}
+			}
+			ev := &Event{
+				Workflow: wf,
+			}
+			gh := &fileContentGetter{content: tt.content}
+			err := ReadWorkflowOn(context.Background(), gh, "suzuki-shunsuke", "test-ci", ev)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(tt.exp, ev.On); diff != "" {
+				t.Fatal(diff)
+			}
+			if gh.ref != tt.expRef {
+				t.Fatalf("ref: wanted %s, got %s", tt.expRef, gh.ref)
+			}
+		})
+	}
+}
+
+func Test_filterPatternToRegexp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		pattern  string
+		matched  []string
+		excluded []string
+	}{
+		{
+			name:     "asterisk",
+			pattern:  "feature/*",
+			matched:  []string{"feature/foo"},
+			excluded: []string{"feature/foo/bar", "main"},
+		},
+		{
+			name:     "double asterisk",
+			pattern:  "docs/**",
+			matched:  []string{"docs/README.md", "docs/foo/bar.md"},
+			excluded: []string{"README.md"},
+		},
+		{
+			name:     "double asterisk slash",
+			pattern:  "**/*.md",
+			matched:  []string{"README.md", "docs/foo/bar.md"},
+			excluded: []string{"main.go"},
+		},
+		{
+			name:     "plus and character class",
+			pattern:  "v[12].[0-9]+",
+			matched:  []string{"v1.0", "v2.10"},
+			excluded: []string{"v3.0", "v1.a"},
+		},
+		{
+			name:     "question",
+			pattern:  "*.jsx?",
+			matched:  []string{"a.js", "a.jsx"},
+			excluded: []string{"a.jsxx"},
+		},
+		{
+			name:     "quantifier after asterisk is literal",
+			pattern:  "*?",
+			matched:  []string{"foo?", "?"},
+			excluded: []string{"foo"},
+		},
+		{
+			name:     "leading quantifiers are literal",
+			pattern:  "+foo",
+			matched:  []string{"+foo"},
+			excluded: []string{"foo", "ffoo"},
+		},
+		{
+			name:     "quantifier after quantifier is literal",
+			pattern:  "a+?",
+			matched:  []string{"a?", "aa?"},
+			excluded: []string{"a", "aa"},
+		},
+		{
+			name:     "quantifier after double asterisk is literal",
+			pattern:  "**/+",
+			matched:  []string{"+", "foo/+"},
+			excluded: []string{"foo/bar"},
+		},
+		{
+			name:     "quantifier after unclosed bracket",
+			pattern:  "[+",
+			matched:  []string{"[", "[["},
+			excluded: []string{"+"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := regexp.MustCompile(filterPatternToRegexp(tt.pattern))
+			for _, s := range tt.matched {
+				if !p.MatchString(s) {
+					t.Fatalf("%s must match %s", p, s)
+				}
+			}
+			for _, s := range tt.excluded {
+				if p.MatchString(s) {
+					t.Fatalf("%s must not match %s", p, s)
+				}
+			}
+		})
+	}
+}
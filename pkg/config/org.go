@@ -0,0 +1,64 @@
+package config
+
+// Org is the default configuration of repositories whose repo_owner is Name.
+// Fields which aren't set in Repo are inherited.
+// A repository whose repo_owner_match matches Name inherits them too, as if repo_owner were Name.
+type Org struct {
+	Name                  string `validate:"required"`
+	WorkflowGitHubAppName string `yaml:"workflow_github_app_name"`
+	CIRepoName            string `yaml:"ci_repo_name"`
+	// Events are inherited if the repository has no event
+	Events              []*Event
+	ChangedFilesUnknown string            `yaml:"changed_files_unknown"`
+	MergeablePolling    *MergeablePolling `yaml:"mergeable_polling"`
+	DryRun              bool              `yaml:"dry_run"`
+	RepoConfig          *RepoConfig       `yaml:"repo_config"`
+}
+
+func (org *Org) apply(repo *Repo) {
+	if repo.WorkflowGitHubAppName == "" {
+		repo.WorkflowGitHubAppName = org.WorkflowGitHubAppName
+	}
+	if repo.CIRepoName == "" {
+		repo.CIRepoName = org.CIRepoName
+	}
+	if repo.ChangedFilesUnknown == "" {
+		repo.ChangedFilesUnknown = org.ChangedFilesUnknown
+	}
+	if repo.MergeablePolling == nil {
+		repo.MergeablePolling = org.MergeablePolling
+	}
+	if org.DryRun {
+		repo.DryRun = true
+	}
+	if repo.RepoConfig == nil {
+		repo.RepoConfig = org.RepoConfig
+	}
+	if len(repo.Events) == 0 {
+		repo.Events = copyEvents(org.Events)
+	}
+}
+
+// copyEvents copies events so that each repository's events are initialized and bound to GitHub Apps independently.
+func copyEvents(events []*Event) []*Event {
+	if events == nil {
+		return nil
+	}
+	evs := make([]*Event, len(events))
+	for i, ev := range events {
+		e := *ev
+		if ev.Workflow != nil {
+			wf := *ev.Workflow
+			e.Workflow = &wf
+		}
+		if ev.Matches != nil {
+			e.Matches = make([]*Match, len(ev.Matches))
+			for j, m := range ev.Matches {
+				match := *m
+				e.Matches[j] = &match
+			}
+		}
+		evs[i] = &e
+	}
+	return evs
+}
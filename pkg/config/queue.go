@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+)
+
+// Queue is the setting of the asynchronous mode.
+// If it's set, a webhook request is validated and enqueued, then a worker routes and dispatches it.
+// Only Amazon SQS is supported because gha-trigger runs on AWS Lambda.
+// Google Cloud Pub/Sub isn't supported, and an in-process channel isn't either
+// because messages in it are lost when the function instance is frozen or recycled.
+// The worker is the same function invoked by the SQS event source mapping,
+// which must set ReportBatchItemFailures in function_response_types so that only failed messages are retried.
+type Queue struct {
+	// sqs
+	Type string
+	// QueueURL is the URL of the Amazon SQS queue.
+	// Retries and the dead-letter queue are configured by the queue's redrive policy.
+	QueueURL string `yaml:"queue_url"`
+}
+
+const QueueTypeSQS = "sqs"
+
+var (
+	errInvalidQueueType = errors.New("queue type must be sqs")
+	errQueueURLRequired = errors.New("queue_url is required")
+)
+
+func (q *Queue) Validate() error {
+	if q == nil {
+		return nil
+	}
+	switch q.Type {
+	case QueueTypeSQS:
+		if q.QueueURL == "" {
+			return errQueueURLRequired
+		}
+		return nil
+	default:
+		return errInvalidQueueType
+	}
+}
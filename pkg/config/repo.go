@@ -0,0 +1,217 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// IsPattern returns true if the repository configuration matches multiple repositories.
+func (repo *Repo) IsPattern() bool {
+	return repo.RepoOwner == "" || repo.RepoName == ""
+}
+
+// Matches returns true if the repository configuration matches the repository.
+func (repo *Repo) Matches(owner, name string) (bool, error) {
+	f, err := matchRepoField(repo.RepoOwner, repo.RepoOwnerMatch, owner)
+	if err != nil || !f {
+		return false, err
+	}
+	return matchRepoField(repo.RepoName, repo.RepoNameMatch, name)
+}
+
+func matchRepoField(exact string, sm *StringMatch, s string) (bool, error) {
+	if exact != "" {
+		return exact == s, nil
+	}
+	if sm == nil {
+		return false, errRepoFieldRequired
+	}
+	return sm.Match(s)
+}
+
+type ciRepoNameParam struct {
+	RepoOwner string
+	RepoName  string
+}
+
+func (repo *Repo) renderCIRepoName(owner, name string) (string, error) {
+	if repo.ciRepoName == nil {
+		return repo.CIRepoName, nil
+	}
+	buf := &strings.Builder{}
+	if err := repo.ciRepoName.Execute(buf, &ciRepoNameParam{
+		RepoOwner: owner,
+		RepoName:  name,
+	}); err != nil {
+		return "", fmt.Errorf("render ci_repo_name: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// GetRepo returns the configuration of the repository.
+// Repositories whose owner and name are set are prioritized over patterns.
+// If a pattern matches, a copy whose owner, name, and CI repository name are resolved is returned.
+// If it isn't found, nil is returned.
+func GetRepo(repos []*Repo, owner, name string) (*Repo, error) {
+	for _, repo := range repos {
+		if repo.RepoOwner == owner && repo.RepoName == name {
+			return repo, nil
+		}
+	}
+	for _, repo := range repos {
+		if !repo.IsPattern() {
+			continue
+		}
+		f, err := repo.Matches(owner, name)
+		if err != nil {
+			return nil, err
+		}
+		if !f {
+			continue
+		}
+		ciRepoName, err := repo.renderCIRepoName(owner, name)
+		if err != nil {
+			return nil, err
+		}
+		r := *repo
+		r.RepoOwner = owner
+		r.RepoName = name
+		r.CIRepoName = ciRepoName
+		r.RepoOwnerMatch = nil
+		r.RepoNameMatch = nil
+		r.ciRepoName = nil
+		return &r, nil
+	}
+	return nil, nil
+}
+
+var (
+	errWorkflowGitHubAppNameRequired = errors.New("workflow_github_app_name is required")
+	errCIRepoNameRequired            = errors.New("ci_repo_name is required")
+	errPatternUnsupported            = errors.New("schedules and on_from_workflow aren't supported if repo_owner or repo_name isn't set")
+	errRepoFieldRequired             = errors.New("either repo_owner or repo_owner_match and either repo_name or repo_name_match are required")
+)
+
+// Prepare applies Orgs to Repos and compiles repository patterns and CI repository names.
+// It must be called before GitHub Apps are bound to workflows and Init is called.
+// A repository whose owner is a pattern is expanded by applyOrgs, so Repos may be replaced.
+func Prepare(cfg *Config) error {
+	repos := make([]*Repo, 0, len(cfg.Repos))
+	for _, repo := range cfg.Repos {
+		rs, err := applyOrgs(repo, cfg.Orgs)
+		if err != nil {
+			return fmt.Errorf("prepare the repository configuration (owner: %s, name: %s): %w", repo.RepoOwner, repo.RepoName, err)
+		}
+		repos = append(repos, rs...)
+	}
+	cfg.Repos = repos
+	return nil
+}
+
+// applyOrgs applies the org to repo and prepares it.
+// Org defaults need the resolved owner, so if the owner is a pattern (repo_owner_match),
+// a copy of repo is created per org whose name matches the pattern and the org is applied to the copy.
+// The copies precede repo so that they are found first by GetRepo.
+// repo itself is kept for the other owners if it's valid without org defaults.
+func applyOrgs(repo *Repo, orgs []*Org) ([]*Repo, error) {
+	if repo.RepoOwner != "" || repo.RepoOwnerMatch == nil {
+		for _, org := range orgs {
+			if org.Name == repo.RepoOwner {
+				org.apply(repo)
+				break
+			}
+		}
+		if err := prepareRepo(repo); err != nil {
+			return nil, err
+		}
+		return []*Repo{repo}, nil
+	}
+	if err := repo.RepoOwnerMatch.Validate(); err != nil {
+		return nil, err
+	}
+	if err := repo.RepoOwnerMatch.Compile(); err != nil {
+		return nil, err
+	}
+	var repos []*Repo
+	for _, org := range orgs {
+		f, err := repo.RepoOwnerMatch.Match(org.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !f {
+			continue
+		}
+		r := *repo
+		r.RepoOwner = org.Name
+		r.RepoOwnerMatch = nil
+		r.Events = copyEvents(repo.Events)
+		org.apply(&r)
+		if err := prepareRepo(&r); err != nil {
+			return nil, fmt.Errorf("apply the org %s: %w", org.Name, err)
+		}
+		repos = append(repos, &r)
+	}
+	if err := prepareRepo(repo); err != nil {
+		if len(repos) != 0 {
+			// required fields are given by org defaults, so only the owners of orgs are matched
+			return repos, nil
+		}
+		return nil, err
+	}
+	return append(repos, repo), nil
+}
+
+func prepareRepo(repo *Repo) error {
+	if repo.WorkflowGitHubAppName == "" {
+		return errWorkflowGitHubAppNameRequired
+	}
+	if repo.CIRepoName == "" {
+		return errCIRepoNameRequired
+	}
+	if (repo.RepoOwner == "" && repo.RepoOwnerMatch == nil) || (repo.RepoName == "" && repo.RepoNameMatch == nil) {
+		return errRepoFieldRequired
+	}
+	for _, sm := range []*StringMatch{repo.RepoOwnerMatch, repo.RepoNameMatch} {
+		if sm == nil {
+			continue
+		}
+		if err := sm.Validate(); err != nil {
+			return err
+		}
+		if err := sm.Compile(); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(repo.CIRepoName, "{{") {
+		tpl, err := template.New("ci_repo_name").Parse(repo.CIRepoName)
+		if err != nil {
+			return fmt.Errorf("parse ci_repo_name as Go template: %w", err)
+		}
+		repo.ciRepoName = tpl
+	}
+	for _, wf := range repo.workflows() {
+		if err := wf.compileRef(); err != nil {
+			return err
+		}
+	}
+	if !repo.IsPattern() {
+		ciRepoName, err := repo.renderCIRepoName(repo.RepoOwner, repo.RepoName)
+		if err != nil {
+			return err
+		}
+		repo.CIRepoName = ciRepoName
+		repo.ciRepoName = nil
+		return nil
+	}
+	if len(repo.Schedules) != 0 {
+		return errPatternUnsupported
+	}
+	for _, ev := range repo.Events {
+		if ev.OnFromWorkflow {
+			return errPatternUnsupported
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultRepoConfigPath is the default path of the configuration file in the source repository.
+const DefaultRepoConfigPath = "gha-trigger.yaml"
+
+// RepoConfig enables to read the repository's events from the file in the source repository's default branch.
+// The file is merged with the central configuration, and the central configuration restricts what the file can change.
+type RepoConfig struct {
+	// Path is the file path in the source repository. The default is gha-trigger.yaml
+	Path string
+	// AllowedCIRepos are CI repositories which the file can set as ci_repo_name in addition to Repo.CIRepoName
+	AllowedCIRepos []string `yaml:"allowed_ci_repos"`
+	// AllowedGitHubApps are GitHub Apps which the file can set as workflow_github_app_name in addition to Repo.WorkflowGitHubAppName
+	AllowedGitHubApps []string `yaml:"allowed_github_apps"`
+	// CacheTTL is how long the file is used without reading it again. The default is 1 minute.
+	// If github_api_cache is set, the file is read by a conditional request, which doesn't count against the rate limit.
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+const defaultRepoConfigCacheTTL = time.Minute
+
+func (rc *RepoConfig) GetCacheTTL() time.Duration {
+	if rc == nil || rc.CacheTTL <= 0 {
+		return defaultRepoConfigCacheTTL
+	}
+	return rc.CacheTTL
+}
+
+func (rc *RepoConfig) GetPath() string {
+	if rc == nil || rc.Path == "" {
+		return DefaultRepoConfigPath
+	}
+	return rc.Path
+}
+
+// RepoFile is the configuration file in the source repository.
+// If the file exists, Events override the central configuration's events.
+type RepoFile struct {
+	CIRepoName            string `yaml:"ci_repo_name"`
+	WorkflowGitHubAppName string `yaml:"workflow_github_app_name"`
+	Events                []*Event
+}
+
+var (
+	errCIRepoNotAllowed    = errors.New("the CI repository isn't allowed")
+	errGitHubAppNotAllowed = errors.New("the GitHub App isn't allowed")
+)
+
+// MergeRepoFile returns a copy of the central repository configuration merged with the file in the source repository.
+// central isn't changed. The returned Repo must be initialized by InitEvents.
+func MergeRepoFile(central *Repo, file *RepoFile) (*Repo, error) {
+	repo := *central
+	if file.CIRepoName != "" {
+		if file.CIRepoName != central.CIRepoName && !contains(central.RepoConfig.AllowedCIRepos, file.CIRepoName) {
+			return nil, fmt.Errorf("%w: %s", errCIRepoNotAllowed, file.CIRepoName)
+		}
+		repo.CIRepoName = file.CIRepoName
+	}
+	if file.WorkflowGitHubAppName != "" {
+		if file.WorkflowGitHubAppName != central.WorkflowGitHubAppName && !contains(central.RepoConfig.AllowedGitHubApps, file.WorkflowGitHubAppName) {
+			return nil, fmt.Errorf("%w: %s", errGitHubAppNotAllowed, file.WorkflowGitHubAppName)
+		}
+		repo.WorkflowGitHubAppName = file.WorkflowGitHubAppName
+	}
+	for _, ev := range file.Events {
+		if err := Validate(ev); err != nil {
+			return nil, fmt.Errorf("event is invalid: %w", err)
+		}
+		if err := validateWorkflowOverride(central, ev.Workflow); err != nil {
+			return nil, err
+		}
+	}
+	repo.Events = file.Events
+	return &repo, nil
+}
+
+// validateWorkflowOverride confirms the file doesn't run workflows out of the guardrails.
+func validateWorkflowOverride(central *Repo, wf *Workflow) error {
+	if wf.RepoOwner != "" && wf.RepoOwner != central.RepoOwner {
+		return fmt.Errorf("%w: %s/%s", errCIRepoNotAllowed, wf.RepoOwner, wf.RepoName)
+	}
+	if wf.RepoName != "" && wf.RepoName != central.CIRepoName && !contains(central.RepoConfig.AllowedCIRepos, wf.RepoName) {
+		return fmt.Errorf("%w: %s", errCIRepoNotAllowed, wf.RepoName)
+	}
+	if wf.GitHubAppName != "" && wf.GitHubAppName != central.WorkflowGitHubAppName && !contains(central.RepoConfig.AllowedGitHubApps, wf.GitHubAppName) {
+		return fmt.Errorf("%w: %s", errGitHubAppNotAllowed, wf.GitHubAppName)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
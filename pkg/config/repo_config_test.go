@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeRepoFile(t *testing.T) {
+	t.Parallel()
+	central := &config.Repo{
+		RepoOwner:             "suzuki-shunsuke",
+		RepoName:              "foo",
+		WorkflowGitHubAppName: "ci",
+		CIRepoName:            "foo-ci",
+		RepoConfig: &config.RepoConfig{
+			AllowedCIRepos:    []string{"foo-ci-2"},
+			AllowedGitHubApps: []string{"ci-2"},
+		},
+	}
+	events := []*config.Event{
+		{
+			Workflow: &config.Workflow{
+				WorkflowFileName: "test.yaml",
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		wantErr bool
+		file    *config.RepoFile
+		exp     *config.Repo
+	}{
+		{
+			name: "events",
+			file: &config.RepoFile{
+				Events: events,
+			},
+			exp: &config.Repo{
+				RepoOwner:             "suzuki-shunsuke",
+				RepoName:              "foo",
+				WorkflowGitHubAppName: "ci",
+				CIRepoName:            "foo-ci",
+				RepoConfig:            central.RepoConfig,
+				Events:                events,
+			},
+		},
+		{
+			name: "allowed",
+			file: &config.RepoFile{
+				CIRepoName:            "foo-ci-2",
+				WorkflowGitHubAppName: "ci-2",
+			},
+			exp: &config.Repo{
+				RepoOwner:             "suzuki-shunsuke",
+				RepoName:              "foo",
+				WorkflowGitHubAppName: "ci-2",
+				CIRepoName:            "foo-ci-2",
+				RepoConfig:            central.RepoConfig,
+			},
+		},
+		{
+			name:    "ci repo isn't allowed",
+			wantErr: true,
+			file: &config.RepoFile{
+				CIRepoName: "bar-ci",
+			},
+		},
+		{
+			name:    "github app isn't allowed",
+			wantErr: true,
+			file: &config.RepoFile{
+				WorkflowGitHubAppName: "admin",
+			},
+		},
+		{
+			name:    "workflow's repository isn't allowed",
+			wantErr: true,
+			file: &config.RepoFile{
+				Events: []*config.Event{
+					{
+						Workflow: &config.Workflow{
+							WorkflowFileName: "test.yaml",
+							RepoOwner:        "suzuki-shunsuke",
+							RepoName:         "security-scan",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "workflow is required",
+			wantErr: true,
+			file: &config.RepoFile{
+				Events: []*config.Event{{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo, err := config.MergeRepoFile(central, tt.file)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(tt.exp, repo, cmp.AllowUnexported(config.StringMatch{}, config.Repo{}, config.Workflow{})); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+)
+
+func TestPrepare(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		wantErr        bool
+		cfg            *config.Config
+		owner          string
+		repoName       string
+		expNotFound    bool
+		expCIRepoName  string
+		expAppName     string
+		expEventsCount int
+	}{
+		{
+			name: "org defaults and template",
+			cfg: &config.Config{
+				Orgs: []*config.Org{
+					{
+						Name:                  "suzuki-shunsuke",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "{{ .RepoName }}-ci",
+						Events: []*config.Event{
+							{
+								Workflow: &config.Workflow{
+									WorkflowFileName: "test.yaml",
+								},
+							},
+						},
+					},
+				},
+				Repos: []*config.Repo{
+					{
+						RepoOwner: "suzuki-shunsuke",
+						RepoName:  "foo",
+					},
+				},
+			},
+			owner:          "suzuki-shunsuke",
+			repoName:       "foo",
+			expCIRepoName:  "foo-ci",
+			expAppName:     "ci",
+			expEventsCount: 1,
+		},
+		{
+			name: "pattern",
+			cfg: &config.Config{
+				Orgs: []*config.Org{
+					{
+						Name:                  "suzuki-shunsuke",
+						WorkflowGitHubAppName: "ci",
+					},
+				},
+				Repos: []*config.Repo{
+					{
+						RepoOwner:  "suzuki-shunsuke",
+						RepoName:   "foo",
+						CIRepoName: "foo-ci-2",
+					},
+					{
+						RepoOwner: "suzuki-shunsuke",
+						RepoNameMatch: &config.StringMatch{
+							Type:  "glob",
+							Value: "*",
+						},
+						CIRepoName: "{{ .RepoOwner }}-{{ .RepoName }}-ci",
+					},
+				},
+			},
+			owner:         "suzuki-shunsuke",
+			repoName:      "bar",
+			expCIRepoName: "suzuki-shunsuke-bar-ci",
+			expAppName:    "ci",
+		},
+		{
+			name: "org defaults are applied to owner patterns",
+			cfg: &config.Config{
+				Orgs: []*config.Org{
+					{
+						Name:                  "suzuki-shunsuke",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "{{ .RepoName }}-ci",
+						Events: []*config.Event{
+							{
+								Workflow: &config.Workflow{
+									WorkflowFileName: "test.yaml",
+								},
+							},
+						},
+					},
+				},
+				Repos: []*config.Repo{
+					{
+						RepoOwnerMatch: &config.StringMatch{
+							Type:  "prefix",
+							Value: "suzuki-",
+						},
+						RepoName: "foo",
+					},
+				},
+			},
+			owner:          "suzuki-shunsuke",
+			repoName:       "foo",
+			expCIRepoName:  "foo-ci",
+			expAppName:     "ci",
+			expEventsCount: 1,
+		},
+		{
+			name: "owner patterns without org defaults match only orgs",
+			cfg: &config.Config{
+				Orgs: []*config.Org{
+					{
+						Name:                  "suzuki-shunsuke",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "ci",
+					},
+				},
+				Repos: []*config.Repo{
+					{
+						RepoOwnerMatch: &config.StringMatch{
+							Type:  "prefix",
+							Value: "suzuki-",
+						},
+						RepoName: "foo",
+					},
+				},
+			},
+			owner:       "suzuki-foo",
+			repoName:    "foo",
+			expNotFound: true,
+		},
+		{
+			name: "owner patterns valid without org defaults match other owners",
+			cfg: &config.Config{
+				Orgs: []*config.Org{
+					{
+						Name:                  "suzuki-shunsuke",
+						WorkflowGitHubAppName: "ci",
+					},
+				},
+				Repos: []*config.Repo{
+					{
+						RepoOwnerMatch: &config.StringMatch{
+							Type:  "prefix",
+							Value: "suzuki-",
+						},
+						RepoName:              "foo",
+						WorkflowGitHubAppName: "default",
+						CIRepoName:            "ci",
+					},
+				},
+			},
+			owner:         "suzuki-foo",
+			repoName:      "foo",
+			expCIRepoName: "ci",
+			expAppName:    "default",
+		},
+		{
+			name:    "owner patterns require org defaults or fields",
+			wantErr: true,
+			cfg: &config.Config{
+				Orgs: []*config.Org{
+					{
+						Name:                  "octocat",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "ci",
+					},
+				},
+				Repos: []*config.Repo{
+					{
+						RepoOwnerMatch: &config.StringMatch{
+							Type:  "prefix",
+							Value: "suzuki-",
+						},
+						RepoName: "foo",
+					},
+				},
+			},
+		},
+		{
+			name: "exact match is prioritized",
+			cfg: &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner: "suzuki-shunsuke",
+						RepoNameMatch: &config.StringMatch{
+							Type:  "glob",
+							Value: "*",
+						},
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "{{ .RepoName }}-ci",
+					},
+					{
+						RepoOwner:             "suzuki-shunsuke",
+						RepoName:              "foo",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "ci",
+					},
+				},
+			},
+			owner:         "suzuki-shunsuke",
+			repoName:      "foo",
+			expCIRepoName: "ci",
+			expAppName:    "ci",
+		},
+		{
+			name: "not found",
+			cfg: &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner: "suzuki-shunsuke",
+						RepoNameMatch: &config.StringMatch{
+							Type:  "prefix",
+							Value: "foo-",
+						},
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "ci",
+					},
+				},
+			},
+			owner:       "suzuki-shunsuke",
+			repoName:    "bar",
+			expNotFound: true,
+		},
+		{
+			name:    "app is required",
+			wantErr: true,
+			cfg: &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner:  "suzuki-shunsuke",
+						RepoName:   "foo",
+						CIRepoName: "ci",
+					},
+				},
+			},
+		},
+		{
+			name:    "repo_name is required",
+			wantErr: true,
+			cfg: &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner:             "suzuki-shunsuke",
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "ci",
+					},
+				},
+			},
+		},
+		{
+			name:    "schedules aren't supported with patterns",
+			wantErr: true,
+			cfg: &config.Config{
+				Repos: []*config.Repo{
+					{
+						RepoOwner: "suzuki-shunsuke",
+						RepoNameMatch: &config.StringMatch{
+							Type:  "glob",
+							Value: "*",
+						},
+						WorkflowGitHubAppName: "ci",
+						CIRepoName:            "ci",
+						Schedules: []*config.Schedule{
+							{
+								Cron: "0 0 * * *",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := config.Prepare(tt.cfg); err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			repo, err := config.GetRepo(tt.cfg.Repos, tt.owner, tt.repoName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.expNotFound {
+				if repo != nil {
+					t.Fatal("repository config must not be found")
+				}
+				return
+			}
+			if repo == nil {
+				t.Fatal("repository config must be found")
+			}
+			if repo.RepoOwner != tt.owner || repo.RepoName != tt.repoName {
+				t.Fatalf("repository must be resolved: %s/%s", repo.RepoOwner, repo.RepoName)
+			}
+			if repo.CIRepoName != tt.expCIRepoName {
+				t.Fatalf("ci_repo_name: wanted %s, got %s", tt.expCIRepoName, repo.CIRepoName)
+			}
+			if repo.WorkflowGitHubAppName != tt.expAppName {
+				t.Fatalf("workflow_github_app_name: wanted %s, got %s", tt.expAppName, repo.WorkflowGitHubAppName)
+			}
+			if len(repo.Events) != tt.expEventsCount {
+				t.Fatalf("events: wanted %d, got %d", tt.expEventsCount, len(repo.Events))
+			}
+		})
+	}
+}
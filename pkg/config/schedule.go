@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type Schedule struct {
+	// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#schedule
+	// The shortest interval is once every minute and the time zone is UTC.
+	Cron     string    `validate:"required"`
+	Workflow *Workflow `validate:"required"`
+	schedule cron.Schedule
+}
+
+func (s *Schedule) Compile() error {
+	schedule, err := cron.ParseStandard(s.Cron)
+	if err != nil {
+		return fmt.Errorf("parse a cron expression %s: %w", s.Cron, err)
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = time.UTC
+	}
+	s.schedule = schedule
+	return nil
+}
+
+// Due returns true if the schedule fires at the minute of t.
+func (s *Schedule) Due(t time.Time) bool {
+	minute := t.UTC().Truncate(time.Minute)
+	return s.schedule.Next(minute.Add(-time.Second)).Equal(minute)
+}
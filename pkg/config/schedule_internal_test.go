@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Due(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		wantErr bool
+		cron    string
+		t       time.Time
+		exp     bool
+	}{
+		{
+			name: "due",
+			cron: "30 5 * * 1-5",
+			t:    time.Date(2022, 8, 1, 5, 30, 12, 0, time.UTC),
+			exp:  true,
+		},
+		{
+			name: "not due",
+			cron: "30 5 * * 1-5",
+			t:    time.Date(2022, 8, 1, 5, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "utc",
+			cron: "0 0 * * *",
+			t:    time.Date(2022, 8, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+			exp:  true,
+		},
+		{
+			name:    "invalid cron",
+			cron:    "foo",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			schedule := &Schedule{
+				Cron: tt.cron,
+			}
+			if err := schedule.Compile(); err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if f := schedule.Due(tt.t); f != tt.exp {
+				t.Fatalf("wanted %v, got %v", tt.exp, f)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/gha-trigger/gha-trigger/pkg/github"
 )
@@ -13,16 +14,57 @@ import (
 type Config struct {
 	AWS        *AWS         `yaml:"aws"`
 	GitHubApps []*GitHubApp `yaml:"github_apps"`
-	Repos      []*Repo
+	// Orgs are the defaults of repositories of each owner
+	Orgs  []*Org
+	Repos []*Repo
+	// MergeablePolling is the default of Repo.MergeablePolling
+	MergeablePolling *MergeablePolling `yaml:"mergeable_polling"`
+	Queue            *Queue
+	Audit            *Audit
+	// GitHubAPICache caches responses of GitHub API GET requests
+	GitHubAPICache *GitHubAPICache `yaml:"github_api_cache"`
+	// If DryRun is true, workflows and slash commands aren't run in all repositories
+	DryRun bool `yaml:"dry_run"`
 }
 
 type Repo struct {
-	RepoOwner             string `yaml:"repo_owner" validate:"required"`
-	RepoName              string `yaml:"repo_name" validate:"required"`
-	WorkflowGitHubAppName string `yaml:"workflow_github_app_name" validate:"required"`
-	CIRepoName            string `yaml:"ci_repo_name" validate:"required"`
-	Events                []*Event
-	GitHub                *github.Client `yaml:"-"`
+	RepoOwner string `yaml:"repo_owner" validate:"required_without=RepoOwnerMatch"`
+	RepoName  string `yaml:"repo_name" validate:"required_without=RepoNameMatch"`
+	// RepoOwnerMatch and RepoNameMatch are used to match multiple repositories when RepoOwner and RepoName aren't set
+	RepoOwnerMatch *StringMatch `yaml:"repo_owner_match"`
+	RepoNameMatch  *StringMatch `yaml:"repo_name_match"`
+	// WorkflowGitHubAppName and CIRepoName are required, but they can be inherited from Org
+	WorkflowGitHubAppName string `yaml:"workflow_github_app_name"`
+	// CIRepoName is a Go template. RepoOwner and RepoName can be referred, e.g. {{ .RepoName }}-ci
+	CIRepoName string `yaml:"ci_repo_name"`
+	ciRepoName *template.Template
+	Events     []*Event
+	Schedules  []*Schedule
+	// ChangedFilesUnknown is the default of Match.ChangedFilesUnknown
+	ChangedFilesUnknown string            `yaml:"changed_files_unknown"`
+	MergeablePolling    *MergeablePolling `yaml:"mergeable_polling"`
+	// If DryRun is true, routing is evaluated but workflows and slash commands aren't run.
+	// Workflows which would be run are logged.
+	DryRun bool `yaml:"dry_run"`
+	// If RepoConfig is set, events are read from the file in the source repository
+	RepoConfig *RepoConfig    `yaml:"repo_config"`
+	GitHub     *github.Client `yaml:"-"`
+}
+
+type GitHubAPICache struct {
+	// memory
+	Type string `validate:"required,oneof=memory"`
+	// MaxBytes is the max total size of cached responses. If it's 0, 64 MiB is used
+	MaxBytes int64 `yaml:"max_bytes" validate:"gte=0"`
+}
+
+const DefaultGitHubAPICacheMaxBytes = 64 << 20
+
+func (c *GitHubAPICache) GetMaxBytes() int64 {
+	if c == nil || c.MaxBytes == 0 {
+		return DefaultGitHubAPICacheMaxBytes
+	}
+	return c.MaxBytes
 }
 
 type AWS struct {
@@ -43,6 +85,12 @@ type GitHubApp struct {
 	AppID          int64                  `yaml:"app_id"`
 	InstallationID int64                  `json:"installation_id"`
 	Secret         *GitHubAppSecretConfig `validate:"required"`
+	// BaseURL and UploadURL are URLs of GitHub Enterprise Server, e.g. https://ghes.example.com/api/v3/
+	// If UploadURL is empty, it's derived from BaseURL.
+	BaseURL   string `yaml:"base_url" validate:"omitempty,url"`
+	UploadURL string `yaml:"upload_url" validate:"omitempty,url"`
+	// CABundles are file paths of PEM encoded certificates trusted in addition to the system's ones
+	CABundles []string `yaml:"ca_bundles"`
 }
 
 type GitHubAppSecretConfig struct {
@@ -60,8 +108,12 @@ type GitHubAppSecret struct {
 
 type Event struct {
 	// OR Condition
-	Matches  []*Match
-	Workflow *Workflow `validate:"required"`
+	Matches []*Match
+	// On is compiled to Matches and appended to Matches
+	On *On `yaml:"on"`
+	// If OnFromWorkflow is true, On is read from the workflow file in the CI repository
+	OnFromWorkflow bool      `yaml:"on_from_workflow"`
+	Workflow       *Workflow `validate:"required"`
 }
 
 type StringMatch struct {
@@ -127,16 +179,49 @@ type Match struct {
 	PathsIgnore    []*StringMatch `yaml:"paths-ignore"`
 	If             string
 	CompiledIf     string `yaml:"-"`
+	// ChangedFilesUnknown is the policy of paths and paths-ignore when the full list of changed files can't be got.
+	// fail_open (default): paths and paths-ignore are regarded as matched, so the workflow is run.
+	// fail_closed: paths and paths-ignore are regarded as unmatched, so the workflow isn't run.
+	ChangedFilesUnknown string `yaml:"changed_files_unknown"`
+}
+
+const (
+	ChangedFilesUnknownFailOpen   = "fail_open"
+	ChangedFilesUnknownFailClosed = "fail_closed"
+)
+
+var errInvalidChangedFilesUnknown = errors.New("changed_files_unknown must be either fail_open or fail_closed")
+
+func validateChangedFilesUnknown(s string) error {
+	switch s {
+	case "", ChangedFilesUnknownFailOpen, ChangedFilesUnknownFailClosed:
+		return nil
+	default:
+		return errInvalidChangedFilesUnknown
+	}
 }
 
 type Workflow struct {
 	WorkflowFileName string `yaml:"workflow_file_name" validate:"required"`
-	Ref              string
-	GitHub           GitHubWorkflowClient `yaml:"-"`
+	// Ref is a Go template rendered with RefParam, e.g. {{ .BaseRef }}
+	Ref string
+	// RefFallback is used if the rendered Ref is empty or doesn't exist in the repository
+	RefFallback string `yaml:"ref_fallback"`
+	// RepoOwner, RepoName, and GitHubAppName override Repo.RepoOwner, Repo.CIRepoName, and Repo.WorkflowGitHubAppName.
+	// They enable to run workflows in multiple repositories such as a shared security scan repository.
+	RepoOwner     string `yaml:"repo_owner"`
+	RepoName      string `yaml:"repo_name"`
+	GitHubAppName string `yaml:"github_app_name"`
+	// If RunHeadIfNotMergeable is true, the workflow is run against the head commit when the pull request isn't mergeable
+	RunHeadIfNotMergeable bool                 `yaml:"run_head_if_not_mergeable"`
+	GitHub                GitHubWorkflowClient `yaml:"-"`
+	// ref is compiled Ref if Ref is a template
+	ref *template.Template
 }
 
 type GitHubWorkflowClient interface {
 	RunWorkflow(ctx context.Context, owner, repo, workflowFileName string, event github.CreateWorkflowDispatchEventRequest) (*github.Response, error)
+	RefExists(ctx context.Context, owner, repo, ref string) (bool, error)
 }
 
 func compileStringsByRegexp(arr []*StringMatch) error {
@@ -1,10 +1,26 @@
 package config
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New()
+var validate = newValidator() //nolint:gochecknoglobals
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// use YAML keys as field names of errors
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0] //nolint:gomnd
+		if name == "" {
+			return strings.ToLower(field.Name)
+		}
+		return name
+	})
+	return v
+}
 
 func Validate(input interface{}) error {
 	return validate.Struct(input)
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+)
+
+// GetRepoOwner returns the owner of the repository where the workflow is run.
+func (wf *Workflow) GetRepoOwner(repo *Repo) string {
+	if wf.RepoOwner != "" {
+		return wf.RepoOwner
+	}
+	return repo.RepoOwner
+}
+
+// GetRepoName returns the name of the repository where the workflow is run.
+func (wf *Workflow) GetRepoName(repo *Repo) string {
+	if wf.RepoName != "" {
+		return wf.RepoName
+	}
+	return repo.CIRepoName
+}
+
+// GetGitHubAppName returns the name of the GitHub App which runs the workflow.
+func (wf *Workflow) GetGitHubAppName(repo *Repo) string {
+	if wf.GitHubAppName != "" {
+		return wf.GitHubAppName
+	}
+	return repo.WorkflowGitHubAppName
+}
+
+// workflows returns all workflows of the repository including schedules.
+func (repo *Repo) workflows() []*Workflow {
+	wfs := make([]*Workflow, 0, len(repo.Events)+len(repo.Schedules))
+	for _, ev := range repo.Events {
+		wfs = append(wfs, ev.Workflow)
+	}
+	for _, schedule := range repo.Schedules {
+		wfs = append(wfs, schedule.Workflow)
+	}
+	return wfs
+}
+
+// BindGitHubApps sets GitHub App clients to the repository and its workflows.
+func BindGitHubApps(repo *Repo, ghs map[string]*github.Client) error {
+	gh, ok := ghs[repo.WorkflowGitHubAppName]
+	if !ok {
+		return fmt.Errorf("GitHub App isn't found: %s", repo.WorkflowGitHubAppName)
+	}
+	repo.GitHub = gh
+	for _, wf := range repo.workflows() {
+		name := wf.GetGitHubAppName(repo)
+		gh, ok := ghs[name]
+		if !ok {
+			return fmt.Errorf("GitHub App isn't found: %s", name)
+		}
+		wf.GitHub = gh
+	}
+	return nil
+}
+
+type RepoGetter interface {
+	GetRepo(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+}
+
+var errRepoUnreachable = errors.New("GitHub App can't access the repository")
+
+// ValidateWorkflowTargets confirms that the GitHub App of each workflow can access the repository where the workflow is run.
+// Workflows whose repository is resolved per event by patterns are skipped.
+func ValidateWorkflowTargets(ctx context.Context, repo *Repo, ghs map[string]*github.Client) error {
+	checked := map[string]struct{}{}
+	for _, wf := range repo.workflows() {
+		if repo.IsPattern() && (wf.RepoOwner == "" || wf.RepoName == "") {
+			continue
+		}
+		appName := wf.GetGitHubAppName(repo)
+		owner := wf.GetRepoOwner(repo)
+		name := wf.GetRepoName(repo)
+		key := appName + ":" + owner + "/" + name
+		if _, ok := checked[key]; ok {
+			continue
+		}
+		checked[key] = struct{}{}
+		gh, ok := ghs[appName]
+		if !ok {
+			return fmt.Errorf("GitHub App isn't found: %s", appName)
+		}
+		if err := validateRepoReachable(ctx, gh, owner, name); err != nil {
+			return fmt.Errorf("%w (app: %s, repository: %s/%s): %s", errRepoUnreachable, appName, owner, name, err.Error())
+		}
+	}
+	return nil
+}
+
+func validateRepoReachable(ctx context.Context, gh RepoGetter, owner, name string) error {
+	_, resp, err := gh.GetRepo(ctx, owner, name)
+	if err == nil {
+		return nil
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return errors.New("the repository isn't found or the GitHub App isn't installed in it")
+	}
+	return err //nolint:wrapcheck
+}
+
+// RefParam is the parameter to render Workflow.Ref.
+type RefParam struct {
+	// Event is the webhook payload
+	Event     map[string]interface{}
+	EventName string
+	// BaseRef is the base branch of pull_request and merge_group events or the branch of other events
+	BaseRef string
+	// HeadRef is the head branch of pull_request events
+	HeadRef string
+	// Labels are labels of the pull request
+	Labels []string
+}
+
+func refFuncs() template.FuncMap {
+	return template.FuncMap{
+		// labelSuffix returns the suffix of the first label with the prefix.
+		// e.g. {{ labelSuffix .Labels "ci-ref:" }} returns "foo" if the label "ci-ref:foo" is set.
+		"labelSuffix": func(labels []string, prefix string) string {
+			for _, label := range labels {
+				if strings.HasPrefix(label, prefix) {
+					return strings.TrimPrefix(label, prefix)
+				}
+			}
+			return ""
+		},
+	}
+}
+
+func (wf *Workflow) parseRef() (*template.Template, error) {
+	return template.New("ref").Funcs(refFuncs()).Parse(wf.Ref) //nolint:wrapcheck
+}
+
+// IsRefTemplate returns true if Ref is a Go template rendered per event.
+func (wf *Workflow) IsRefTemplate() bool {
+	return strings.Contains(wf.Ref, "{{")
+}
+
+// RenderRef renders Ref. If Ref isn't a template, it's returned as is.
+func (wf *Workflow) RenderRef(param *RefParam) (string, error) {
+	if !wf.IsRefTemplate() {
+		return wf.Ref, nil
+	}
+	tpl := wf.ref
+	if tpl == nil {
+		// the workflow isn't initialized by Prepare or Init
+		t, err := wf.parseRef()
+		if err != nil {
+			return "", fmt.Errorf("parse ref as Go template: %w", err)
+		}
+		tpl = t
+	}
+	buf := &strings.Builder{}
+	if err := tpl.Execute(buf, param); err != nil {
+		return "", fmt.Errorf("render ref: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// compileRef parses Ref as Go template once so that it isn't parsed at every dispatch.
+func (wf *Workflow) compileRef() error {
+	if wf == nil || wf.ref != nil || !wf.IsRefTemplate() {
+		return nil
+	}
+	tpl, err := wf.parseRef()
+	if err != nil {
+		return fmt.Errorf("parse ref as Go template: %w", err)
+	}
+	wf.ref = tpl
+	return nil
+}
@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+)
+
+func TestValidateWorkflowTargets(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/suzuki-shunsuke/foo-ci" {
+			w.Write([]byte(`{"name": "foo-ci"}`)) //nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	v3 := github.NewV3Client(srv.Client())
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3.BaseURL = baseURL
+	ghs := map[string]*github.Client{
+		"ci": github.New(v3),
+	}
+	tests := []struct {
+		name    string
+		wantErr bool
+		repo    *config.Repo
+	}{
+		{
+			name: "reachable",
+			repo: &config.Repo{
+				RepoOwner:             "suzuki-shunsuke",
+				RepoName:              "foo",
+				WorkflowGitHubAppName: "ci",
+				CIRepoName:            "foo-ci",
+				Events: []*config.Event{
+					{
+						Workflow: &config.Workflow{
+							WorkflowFileName: "test.yaml",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "unreachable",
+			wantErr: true,
+			repo: &config.Repo{
+				RepoOwner:             "suzuki-shunsuke",
+				RepoName:              "foo",
+				WorkflowGitHubAppName: "ci",
+				CIRepoName:            "foo-ci",
+				Events: []*config.Event{
+					{
+						Workflow: &config.Workflow{
+							WorkflowFileName: "security-scan.yaml",
+							RepoOwner:        "gha-trigger",
+							RepoName:         "security-scan",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "app isn't found",
+			wantErr: true,
+			repo: &config.Repo{
+				RepoOwner:             "suzuki-shunsuke",
+				RepoName:              "foo",
+				WorkflowGitHubAppName: "ci",
+				CIRepoName:            "foo-ci",
+				Events: []*config.Event{
+					{
+						Workflow: &config.Workflow{
+							WorkflowFileName: "test.yaml",
+							GitHubAppName:    "security",
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := config.ValidateWorkflowTargets(ctx, tt.repo, ghs)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+		})
+	}
+}
+
+func TestLoadWorkflowOn(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/suzuki-shunsuke/foo-ci/contents/.github/workflows/test.yaml" {
+			// on: push
+			w.Write([]byte(`{"type": "file", "encoding": "base64", "content": "b246IHB1c2gK"}`)) //nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	v3 := github.NewV3Client(srv.Client())
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3.BaseURL = baseURL
+	ghs := map[string]*github.Client{
+		"ci": github.New(v3),
+	}
+	repo := &config.Repo{
+		RepoOwner:             "suzuki-shunsuke",
+		RepoName:              "foo",
+		WorkflowGitHubAppName: "ci",
+		CIRepoName:            "foo-ci",
+		Events: []*config.Event{
+			{
+				OnFromWorkflow: true,
+				Workflow: &config.Workflow{
+					WorkflowFileName: "test.yaml",
+				},
+			},
+		},
+	}
+	if err := config.InitEvents(repo); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.Events[0].NeedsWorkflowOn() {
+		t.Fatal("on must not be read at initialization")
+	}
+	r, err := config.LoadWorkflowOn(ctx, repo, ghs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.Events[0].NeedsWorkflowOn() {
+		t.Fatal("the original configuration must not be changed")
+	}
+	ev := r.Events[0]
+	if ev.NeedsWorkflowOn() || len(ev.Matches) != 1 || ev.Matches[0].Events[0].Name != "push" {
+		t.Fatalf("on must be read and compiled: %+v", ev.Matches)
+	}
+}
@@ -0,0 +1,33 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/suzuki-shunsuke/go-osenv/osenv"
+)
+
+// Env reads the configuration from an environment variable.
+type Env struct {
+	osEnv osenv.OSEnv
+	name  string
+}
+
+func NewEnv(osEnv osenv.OSEnv, name string) *Env {
+	return &Env{
+		osEnv: osEnv,
+		name:  name,
+	}
+}
+
+func (src *Env) Read(ctx context.Context, etag string) (*Content, error) {
+	s := src.osEnv.Getenv(src.name)
+	if s == "" {
+		return nil, fmt.Errorf("environment variable '%s' is empty", src.name)
+	}
+	b := []byte(s)
+	return &Content{
+		Body: b,
+		ETag: hash(b),
+	}, nil
+}
@@ -0,0 +1,29 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// File reads the configuration from a local file.
+type File struct {
+	path string
+}
+
+func NewFile(p string) *File {
+	return &File{
+		path: p,
+	}
+}
+
+func (src *File) Read(ctx context.Context, etag string) (*Content, error) {
+	b, err := os.ReadFile(src.path)
+	if err != nil {
+		return nil, fmt.Errorf("read a configuration file: %w", err)
+	}
+	return &Content{
+		Body: b,
+		ETag: hash(b),
+	}, nil
+}
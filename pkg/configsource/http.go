@@ -0,0 +1,57 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTP reads the configuration from a URL.
+type HTTP struct {
+	client *http.Client
+	url    string
+}
+
+func NewHTTP(client *http.Client, u string) *HTTP {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTP{
+		client: client,
+		url:    u,
+	}
+}
+
+func (src *HTTP) Read(ctx context.Context, etag string) (*Content, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create a HTTP request: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := src.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send a HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 { //nolint:gomnd
+		return nil, fmt.Errorf("status code of the HTTP response is %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read a HTTP response body: %w", err)
+	}
+	content := &Content{
+		Body: b,
+		ETag: hash(b),
+	}
+	if e := resp.Header.Get("ETag"); e != "" {
+		content.ETag = e
+	}
+	return content, nil
+}
@@ -0,0 +1,36 @@
+package configsource_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/configsource"
+)
+
+func TestHTTP_Read(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("repos: []")) //nolint:errcheck
+	}))
+	defer srv.Close()
+	src := configsource.NewHTTP(srv.Client(), srv.URL)
+	content, err := src.Read(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.ETag != `"v1"` || string(content.Body) != "repos: []" {
+		t.Fatalf("unexpected content: %s %s", content.ETag, string(content.Body))
+	}
+	if _, err := src.Read(ctx, content.ETag); !errors.Is(err, configsource.ErrNotModified) {
+		t.Fatalf("ErrNotModified must be returned: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package configsource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader reads the configuration from a Source and refreshes it periodically.
+type Loader struct {
+	source   Source
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	etag      string
+	checkedAt time.Time
+}
+
+// NewLoader creates a Loader.
+// If interval is zero, the configuration is never refreshed.
+func NewLoader(source Source, interval time.Duration) *Loader {
+	return &Loader{
+		source:   source,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Load reads the configuration unconditionally.
+func (l *Loader) Load(ctx context.Context) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	content, err := l.source.Read(ctx, "")
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	l.etag = content.ETag
+	l.checkedAt = l.now()
+	return content.Body, nil
+}
+
+// Refresh reads the configuration if the refresh interval has passed since the last check.
+// ErrNotModified is returned if it isn't time to refresh or the configuration isn't modified.
+// The returned ETag isn't saved until Commit is called,
+// so the configuration is read again at the next refresh if it fails to be applied.
+func (l *Loader) Refresh(ctx context.Context) (*Content, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.interval <= 0 {
+		return nil, ErrNotModified
+	}
+	now := l.now()
+	if now.Sub(l.checkedAt) < l.interval {
+		return nil, ErrNotModified
+	}
+	l.checkedAt = now
+	content, err := l.source.Read(ctx, l.etag)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if content.ETag == l.etag {
+		return nil, ErrNotModified
+	}
+	return content, nil
+}
+
+// Commit saves the ETag of the configuration which has been applied.
+func (l *Loader) Commit(etag string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.etag = etag
+}
@@ -0,0 +1,99 @@
+package configsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	contents []*Content
+	reads    int
+}
+
+func (src *fakeSource) Read(ctx context.Context, etag string) (*Content, error) {
+	content := src.contents[src.reads]
+	src.reads++
+	if content.ETag == etag {
+		return nil, ErrNotModified
+	}
+	return content, nil
+}
+
+func TestLoader_Refresh(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := &fakeSource{
+		contents: []*Content{
+			{Body: []byte("a"), ETag: "1"},
+			{Body: []byte("a"), ETag: "1"},
+			{Body: []byte("b"), ETag: "2"},
+			{Body: []byte("b"), ETag: "2"},
+			{Body: []byte("b"), ETag: "2"},
+		},
+	}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	loader := NewLoader(src, time.Minute)
+	loader.now = func() time.Time {
+		return now
+	}
+	b, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "a" {
+		t.Fatalf("wanted a, got %s", string(b))
+	}
+	// the interval hasn't passed
+	now = now.Add(30 * time.Second)
+	if _, err := loader.Refresh(ctx); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("ErrNotModified must be returned: %v", err)
+	}
+	if src.reads != 1 {
+		t.Fatalf("the source must not be read: %d", src.reads)
+	}
+	// not modified
+	now = now.Add(time.Minute)
+	if _, err := loader.Refresh(ctx); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("ErrNotModified must be returned: %v", err)
+	}
+	// modified, but failed to be applied
+	now = now.Add(time.Minute)
+	content, err := loader.Refresh(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content.Body) != "b" {
+		t.Fatalf("wanted b, got %s", string(content.Body))
+	}
+	// the configuration is read again because it isn't committed
+	now = now.Add(time.Minute)
+	content, err = loader.Refresh(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader.Commit(content.ETag)
+	// not modified after commit
+	now = now.Add(time.Minute)
+	if _, err := loader.Refresh(ctx); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("ErrNotModified must be returned: %v", err)
+	}
+}
+
+func TestLoader_Refresh_disabled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := &fakeSource{
+		contents: []*Content{
+			{Body: []byte("a"), ETag: "1"},
+		},
+	}
+	loader := NewLoader(src, 0)
+	if _, err := loader.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Refresh(ctx); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("ErrNotModified must be returned: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type S3Client interface {
+	GetObjectWithContext(ctx aws.Context, input *aws.GetObjectInput, opts ...aws.Option) (*aws.GetObjectOutput, error)
+}
+
+// S3 reads the configuration from an Amazon S3 object.
+type S3 struct {
+	client S3Client
+	bucket string
+	key    string
+}
+
+func NewS3(client S3Client, bucket, key string) *S3 {
+	return &S3{
+		client: client,
+		bucket: bucket,
+		key:    key,
+	}
+}
+
+func (src *S3) Read(ctx context.Context, etag string) (*Content, error) {
+	input := &aws.GetObjectInput{
+		Bucket: util.StrP(src.bucket),
+		Key:    util.StrP(src.key),
+	}
+	if etag != "" {
+		input.IfNoneMatch = util.StrP(etag)
+	}
+	output, err := src.client.GetObjectWithContext(ctx, input)
+	if err != nil {
+		if aws.IsNotModified(err) {
+			return nil, ErrNotModified
+		}
+		return nil, fmt.Errorf("get a configuration from Amazon S3: %w", err)
+	}
+	defer output.Body.Close()
+	b, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read a configuration from Amazon S3: %w", err)
+	}
+	content := &Content{
+		Body: b,
+		ETag: hash(b),
+	}
+	if output.ETag != nil {
+		content.ETag = *output.ETag
+	}
+	return content, nil
+}
@@ -0,0 +1,76 @@
+package configsource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/suzuki-shunsuke/go-osenv/osenv"
+)
+
+// Content is the configuration read from a Source.
+type Content struct {
+	Body []byte
+	// ETag identifies the version of the content.
+	// If the source doesn't support ETag, the hash of the content is used.
+	ETag string
+}
+
+// ErrNotModified is returned when the configuration isn't modified since the given ETag.
+var ErrNotModified = errors.New("configuration isn't modified")
+
+// Source reads the configuration.
+// If etag isn't empty and the source supports conditional requests, ErrNotModified may be returned.
+type Source interface {
+	Read(ctx context.Context, etag string) (*Content, error)
+}
+
+type AWSClient interface {
+	S3Client
+	SSMClient
+}
+
+// New returns a Source from environment variables.
+//
+// CONFIG: the configuration itself
+// CONFIG_SOURCE: the location of the configuration. The following schemes are supported.
+//
+//   - file:///etc/gha-trigger/config.yaml
+//   - s3://<bucket>/<key>
+//   - ssm:///<parameter name>
+//   - https://example.com/config.yaml (http is also supported)
+func New(osEnv osenv.OSEnv, awsClient AWSClient, httpClient *http.Client) (Source, error) {
+	if cfg := osEnv.Getenv("CONFIG"); cfg != "" {
+		return NewEnv(osEnv, "CONFIG"), nil
+	}
+	src := osEnv.Getenv("CONFIG_SOURCE")
+	if src == "" {
+		return nil, errors.New("environment variable 'CONFIG' or 'CONFIG_SOURCE' is required")
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("parse CONFIG_SOURCE as URL: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		return NewFile(u.Path), nil
+	case "s3":
+		return NewS3(awsClient, u.Host, strings.TrimPrefix(u.Path, "/")), nil
+	case "ssm":
+		return NewSSM(awsClient, u.Host+u.Path), nil
+	case "http", "https":
+		return NewHTTP(httpClient, src), nil
+	default:
+		return nil, fmt.Errorf("the scheme of CONFIG_SOURCE is invalid: %s", u.Scheme)
+	}
+}
+
+func hash(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
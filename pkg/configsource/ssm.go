@@ -0,0 +1,52 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type SSMClient interface {
+	GetParameterWithContext(ctx aws.Context, input *aws.GetParameterInput, opts ...aws.Option) (*aws.GetParameterOutput, error)
+}
+
+// SSM reads the configuration from an AWS Systems Manager Parameter Store parameter.
+// SecureString parameters are decrypted.
+type SSM struct {
+	client SSMClient
+	name   string
+}
+
+func NewSSM(client SSMClient, name string) *SSM {
+	return &SSM{
+		client: client,
+		name:   name,
+	}
+}
+
+func (src *SSM) Read(ctx context.Context, etag string) (*Content, error) {
+	output, err := src.client.GetParameterWithContext(ctx, &aws.GetParameterInput{
+		Name:           util.StrP(src.name),
+		WithDecryption: util.BoolP(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get a configuration from AWS Systems Manager Parameter Store: %w", err)
+	}
+	param := output.Parameter
+	if param == nil || param.Value == nil {
+		return nil, fmt.Errorf("the parameter is empty: %s", src.name)
+	}
+	b := []byte(*param.Value)
+	content := &Content{
+		Body: b,
+		ETag: hash(b),
+	}
+	// Parameter Store doesn't support conditional requests, so the version is used as ETag.
+	if param.Version != nil {
+		content.ETag = strconv.FormatInt(*param.Version, 10)
+	}
+	return content, nil
+}
@@ -2,30 +2,86 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gha-trigger/gha-trigger/pkg/config"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 	"github.com/gha-trigger/gha-trigger/pkg/github"
 	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
 	"github.com/gha-trigger/gha-trigger/pkg/route"
 	"github.com/gha-trigger/gha-trigger/pkg/runworkflow"
 	"github.com/gha-trigger/gha-trigger/pkg/slashcommand"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// Do handles a webhook request.
+// If the queue is configured, the request is enqueued after validation and processed by the worker.
 func (ctrl *Controller) Do(ctx context.Context, logger *zap.Logger, req *domain.Request) error {
 	toUpperHeaders(req.Params)
+	ctx, span := startRequestSpan(ctx, "controller.Do", req)
+	err := ctrl.handle(ctx, logger, req, span)
+	tracing.End(span, err)
+	return err
+}
 
-	ghApp, ev, err := ctrl.validate(logger, req)
+func (ctrl *Controller) handle(ctx context.Context, logger *zap.Logger, req *domain.Request, span trace.Span) error {
+	ghApp, ev, err := ctrl.validate(ctx, logger, req)
 	if err != nil {
 		return err
 	}
+	setEventAttributes(span, ev)
+	// Process isn't counted because it's called again by the worker for the enqueued request
+	metrics.RecordWebhook(ctx, ev.Type, ev.Payload.Repo.GetFullName())
+	if ctrl.queue == nil {
+		return ctrl.do(ctx, logger.With(zap.String("event_type", ev.Type)), ghApp, ev)
+	}
+	logger.Info("enqueue a request", zap.String("event_type", ev.Type))
+	if err := ctrl.queue.Enqueue(ctx, req); err != nil {
+		return fmt.Errorf("enqueue a request: %w", err)
+	}
+	return nil
+}
+
+// Process validates, routes, and dispatches a webhook request synchronously.
+func (ctrl *Controller) Process(ctx context.Context, logger *zap.Logger, req *domain.Request) error {
+	toUpperHeaders(req.Params)
+	ctx, span := startRequestSpan(ctx, "controller.Process", req)
+	err := ctrl.process(ctx, logger, req, span)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctrl *Controller) process(ctx context.Context, logger *zap.Logger, req *domain.Request, span trace.Span) error {
+	ghApp, ev, err := ctrl.validate(ctx, logger, req)
+	if err != nil {
+		return err
+	}
+	setEventAttributes(span, ev)
 	logger = logger.With(zap.String("event_type", ev.Type))
 
 	return ctrl.do(ctx, logger, ghApp, ev)
 }
 
+// startRequestSpan starts a span of a webhook request.
+// Headers must be normalized by toUpperHeaders.
+func startRequestSpan(ctx context.Context, name string, req *domain.Request) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name, tracing.AttrDeliveryID.String(req.Params.Headers["X-GITHUB-DELIVERY"]))
+}
+
+func setEventAttributes(span trace.Span, ev *domain.Event) {
+	span.SetAttributes(
+		tracing.AttrEventType.String(ev.Type),
+		tracing.AttrRepo.String(ev.Payload.Repo.GetFullName()),
+	)
+}
+
 func toUpperHeaders(params *domain.RequestParamsField) {
 	// Normalize headers
 	headers := make(map[string]string, len(params.Headers))
@@ -35,13 +91,8 @@ func toUpperHeaders(params *domain.RequestParamsField) {
 	params.Headers = headers
 }
 
-func getRepoConfig(ghRepo *github.Repository, repos []*config.Repo) *config.Repo {
-	for _, repo := range repos {
-		if repo.RepoOwner == ghRepo.GetOwner().GetLogin() && repo.RepoName == ghRepo.GetName() {
-			return repo
-		}
-	}
-	return nil
+func getRepoConfig(ghRepo *github.Repository, repos []*config.Repo) (*config.Repo, error) {
+	return config.GetRepo(repos, ghRepo.GetOwner().GetLogin(), ghRepo.GetName())
 }
 
 func (ctrl *Controller) do(ctx context.Context, logger *zap.Logger, ghApp *githubapp.GitHubApp, ev *domain.Event) error {
@@ -50,28 +101,79 @@ func (ctrl *Controller) do(ctx context.Context, logger *zap.Logger, ghApp *githu
 		return nil
 	}
 
-	repoCfg := getRepoConfig(ev.Payload.Repo, ctrl.cfg.Repos)
+	repoCfg, err := getRepoConfig(ev.Payload.Repo, ctrl.cfg.Repos)
+	if err != nil {
+		return fmt.Errorf("get the repository config: %w", err)
+	}
 	if repoCfg == nil {
 		logger.Error("repository config isn't found")
 		return nil
 	}
 
+	gh, err := getInstallationClient(ghApp, ev)
+	if err != nil {
+		return err
+	}
+	ev.GitHub = gh
+
+	repoCfg, err = ctrl.repos.Get(ctx, logger, repoCfg)
+	if err != nil {
+		return fmt.Errorf("initialize the repository config: %w", err)
+	}
+
+	if repoCfg.RepoConfig != nil {
+		// merge the configuration file in the source repository
+		cfg, err := ctrl.repoConfigs.Get(ctx, logger, gh, repoCfg, ctrl.ghsByName)
+		if err != nil {
+			return err
+		}
+		repoCfg = cfg
+	}
+	repoCfg = withInstallationClient(repoCfg, ghApp.Client, gh)
+
 	logger = logger.With(
 		zap.String("event_repo_owner", repoCfg.RepoOwner),
 		zap.String("event_repo_name", repoCfg.RepoName),
 		zap.String("ci_repo_name", repoCfg.CIRepoName),
 	)
+	if rate := gh.RateLimit(); rate.IsLow(time.Now()) {
+		logger.Warn("the rate limit of GitHub API is low, so non-essential API calls are skipped",
+			zap.Int("github_rate_limit_remaining", rate.Remaining),
+			zap.Time("github_rate_limit_reset", rate.Reset))
+	}
 
-	if slashcommand.Handle(ctx, logger, repoCfg, ev) {
+	if slashcommand.Handle(ctx, logger, ctrl.audit, repoCfg, ev) {
 		return nil
 	}
 
 	// route and filter request
 	// list labels and changed files
-	workflows, err := route.Match(ctx, ev, repoCfg)
+	traces, err := route.Explain(ctx, ev, repoCfg)
 	if err != nil {
 		return err
 	}
+	workflows := route.MatchedWorkflows(traces)
+	logRouting(logger, traces, workflows)
+	for _, workflow := range workflows {
+		metrics.RecordRouteMatch(ctx, ev.Payload.Repo.GetFullName(), workflow.WorkflowFileName)
+	}
+
+	if err := runworkflow.RunWorkflows(ctx, logger, gh, ctrl.audit, ev, repoCfg, workflows); err != nil {
+		if errors.Is(err, runworkflow.ErrWorkflowsFailed) {
+			// the request isn't retried because succeeded workflows would be run again
+			return util.WithWarn(err)
+		}
+		return err
+	}
+	return nil
+}
 
-	return runworkflow.RunWorkflows(ctx, logger, ghApp.Client, ev, repoCfg, workflows)
+// logRouting logs why each workflow is run or not.
+// The trace is logged at debug level, but its summary is always logged if no workflow matches the event
+// so that we can find why CI wasn't run.
+func logRouting(logger *zap.Logger, traces []*route.EventTrace, workflows []*config.Workflow) {
+	if len(workflows) == 0 {
+		logger.Info("no workflow matches the event", zap.Array("routing", route.TraceSummary(traces)))
+	}
+	logger.Debug("routing result", zap.Array("routing", route.Traces(traces)))
 }
@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
 	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+	"github.com/gha-trigger/gha-trigger/pkg/queue"
+	"github.com/gha-trigger/gha-trigger/pkg/repoconfig"
 	"github.com/suzuki-shunsuke/go-osenv/osenv"
 	"go.uber.org/zap"
 )
@@ -11,12 +15,31 @@ type Controller struct {
 	cfg   *config.Config
 	osEnv osenv.OSEnv
 	ghs   map[int64]*githubapp.GitHubApp
+	// ghsByName is a map of GitHub App names and clients
+	ghsByName   map[string]*github.Client
+	queue       queue.Queue
+	repoConfigs *repoconfig.Loader
+	repos       *repoInitializer
+	audit       audit.Sink
 }
 
-func New(cfg *config.Config, logger *zap.Logger, osEnv osenv.OSEnv, ghs map[int64]*githubapp.GitHubApp) *Controller {
+// New creates a controller.
+// If q is nil, requests are processed synchronously.
+// If sink is nil, audit logs aren't recorded.
+// repoConfigs is shared by controllers built at reloads, so configuration files in source repositories aren't read again.
+func New(cfg *config.Config, logger *zap.Logger, osEnv osenv.OSEnv, ghs map[int64]*githubapp.GitHubApp, q queue.Queue, sink audit.Sink, repoConfigs *repoconfig.Loader) *Controller {
+	ghsByName := make(map[string]*github.Client, len(ghs))
+	for _, gh := range ghs {
+		ghsByName[gh.Name] = gh.Client
+	}
 	return &Controller{
-		cfg:   cfg,
-		osEnv: osEnv,
-		ghs:   ghs,
+		cfg:         cfg,
+		osEnv:       osEnv,
+		ghs:         ghs,
+		ghsByName:   ghsByName,
+		queue:       q,
+		repoConfigs: repoConfigs,
+		repos:       newRepoInitializer(ghsByName),
+		audit:       sink,
 	}
 }
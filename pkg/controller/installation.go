@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+)
+
+// getInstallationClient returns the client of the installation which sent the webhook.
+// A GitHub App can be installed in multiple organizations, so the installation is read from the payload.
+// If the payload doesn't have the installation, the configured client is returned.
+func getInstallationClient(ghApp *githubapp.GitHubApp, ev *domain.Event) (*github.Client, error) {
+	installationID := ev.Payload.Installation.GetID()
+	if installationID == 0 || ghApp.App == nil {
+		return ghApp.Client, nil
+	}
+	gh, err := ghApp.App.InstallationClient(installationID)
+	if err != nil {
+		return nil, fmt.Errorf("get the client of the installation %d: %w", installationID, err)
+	}
+	return gh, nil
+}
+
+// withInstallationClient returns a copy of the repository config whose clients are replaced with the installation's client.
+// Only the configured client of the same GitHub App is replaced, and workflows in other owners keep their clients
+// because the installation may not have access to them.
+// The configuration is shared by requests, so it isn't modified.
+func withInstallationClient(repoCfg *config.Repo, defaultClient, gh *github.Client) *config.Repo {
+	if defaultClient == gh {
+		return repoCfg
+	}
+	repo := *repoCfg
+	if repo.GitHub == defaultClient {
+		repo.GitHub = gh
+	}
+	repo.Events = make([]*config.Event, len(repoCfg.Events))
+	for i, ev := range repoCfg.Events {
+		wf := ev.Workflow
+		if wf == nil || wf.GitHub != config.GitHubWorkflowClient(defaultClient) || wf.GetRepoOwner(repoCfg) != repoCfg.RepoOwner {
+			repo.Events[i] = ev
+			continue
+		}
+		e := *ev
+		w := *wf
+		w.GitHub = gh
+		e.Workflow = &w
+		repo.Events[i] = &e
+	}
+	return &repo
+}
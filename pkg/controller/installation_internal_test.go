@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+)
+
+func Test_withInstallationClient(t *testing.T) {
+	t.Parallel()
+	defaultClient := &github.Client{}
+	installationClient := &github.Client{}
+	otherClient := &github.Client{}
+	repoCfg := &config.Repo{
+		RepoOwner:  "gha-trigger",
+		RepoName:   "example",
+		CIRepoName: "example-ci",
+		GitHub:     defaultClient,
+		Events: []*config.Event{
+			{
+				Workflow: &config.Workflow{
+					WorkflowFileName: "test.yaml",
+					GitHub:           defaultClient,
+				},
+			},
+			{
+				// the installation may not have access to other owners
+				Workflow: &config.Workflow{
+					WorkflowFileName: "scan.yaml",
+					RepoOwner:        "security",
+					RepoName:         "scan",
+					GitHub:           defaultClient,
+				},
+			},
+			{
+				Workflow: &config.Workflow{
+					WorkflowFileName: "deploy.yaml",
+					GitHub:           otherClient,
+				},
+			},
+		},
+	}
+	repo := withInstallationClient(repoCfg, defaultClient, installationClient)
+	if repo.GitHub != installationClient {
+		t.Fatal("the repository's client must be replaced")
+	}
+	exps := []*github.Client{installationClient, defaultClient, otherClient}
+	for i, exp := range exps {
+		if repo.Events[i].Workflow.GitHub != config.GitHubWorkflowClient(exp) {
+			t.Fatalf("events[%d]: the client is unexpected", i)
+		}
+	}
+	// the shared configuration isn't modified
+	if repoCfg.GitHub != defaultClient || repoCfg.Events[0].Workflow.GitHub != config.GitHubWorkflowClient(defaultClient) {
+		t.Fatal("the original configuration must not be modified")
+	}
+	if withInstallationClient(repoCfg, defaultClient, defaultClient) != repoCfg {
+		t.Fatal("the configuration must not be copied if the client isn't changed")
+	}
+}
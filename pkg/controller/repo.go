@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"go.uber.org/zap"
+)
+
+// repoInitializer prepares the repository configuration at the first event of the repository instead of startup,
+// so that GitHub API isn't called at startup and a temporary failure of GitHub doesn't fail the startup.
+// It confirms repositories where workflows are run and reads `on` from workflow files.
+// If it fails, it's retried at the next event.
+type repoInitializer struct {
+	ghs map[string]*github.Client
+	mu  sync.Mutex
+	// repos is keyed by the repository's full name because a copy is created per event for patterns
+	repos map[string]*config.Repo
+}
+
+func newRepoInitializer(ghs map[string]*github.Client) *repoInitializer {
+	return &repoInitializer{
+		ghs:   ghs,
+		repos: map[string]*config.Repo{},
+	}
+}
+
+func (ri *repoInitializer) Get(ctx context.Context, logger *zap.Logger, repo *config.Repo) (*config.Repo, error) {
+	key := repo.RepoOwner + "/" + repo.RepoName
+	ri.mu.Lock()
+	r, ok := ri.repos[key]
+	ri.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+	// the lock isn't held while GitHub API is called, so the repository may be initialized concurrently.
+	r, err := config.LoadWorkflowOn(ctx, repo, ri.ghs)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	// A temporary failure of GitHub shouldn't stop events, so this is only a warning.
+	// `gha-trigger validate --check-targets` fails instead.
+	if err := config.ValidateWorkflowTargets(ctx, r, ri.ghs); err != nil {
+		logger.Warn("validate repositories where workflows are run", zap.Error(err))
+	}
+	ri.mu.Lock()
+	ri.repos[key] = r
+	ri.mu.Unlock()
+	return r, nil
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/runworkflow"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// RunSchedules runs workflows whose schedules fire at the minute of t.
+// It should be called every minute.
+// Failures are logged and returned together as a warning, though other schedules are still run.
+// The invocation shouldn't fail, because the retried invocation has the same time and runs succeeded schedules again.
+func (ctrl *Controller) RunSchedules(ctx context.Context, logger *zap.Logger, t time.Time) error {
+	logger = logger.With(zap.String("event_type", "schedule"), zap.Time("schedule_time", t))
+	var errs error
+	for _, repoCfg := range ctrl.cfg.Repos {
+		logger := logger.With(
+			zap.String("event_repo_owner", repoCfg.RepoOwner),
+			zap.String("event_repo_name", repoCfg.RepoName),
+			zap.String("ci_repo_name", repoCfg.CIRepoName),
+		)
+		for _, schedule := range repoCfg.Schedules {
+			if !schedule.Due(t) {
+				continue
+			}
+			logger := logger.With(zap.String("schedule_cron", schedule.Cron))
+			ev := newScheduleEvent(repoCfg, schedule)
+			if err := runworkflow.RunWorkflows(ctx, logger, repoCfg.GitHub, ctrl.audit, ev, repoCfg, []*config.Workflow{schedule.Workflow}); err != nil {
+				logger.Error("run a scheduled workflow", zap.Error(err))
+				errs = multierr.Append(errs, fmt.Errorf("run a scheduled workflow %s of %s/%s: %w",
+					schedule.Workflow.WorkflowFileName, repoCfg.RepoOwner, repoCfg.RepoName, err))
+			}
+		}
+	}
+	if errs != nil {
+		return util.WithWarn(errs)
+	}
+	return nil
+}
+
+func newScheduleEvent(repoCfg *config.Repo, schedule *config.Schedule) *domain.Event {
+	fullName := repoCfg.RepoOwner + "/" + repoCfg.RepoName
+	return &domain.Event{
+		// https://docs.github.com/en/webhooks-and-events/webhooks/webhook-events-and-payloads#schedule
+		Raw: map[string]interface{}{
+			"schedule": schedule.Cron,
+			"repository": map[string]interface{}{
+				"name":      repoCfg.RepoName,
+				"full_name": fullName,
+				"owner": map[string]interface{}{
+					"login": repoCfg.RepoOwner,
+				},
+			},
+		},
+		Type: "schedule",
+		Payload: &domain.Payload{
+			Repo: &github.Repository{
+				Name:     &repoCfg.RepoName,
+				FullName: &fullName,
+				Owner: &github.User{
+					Login: &repoCfg.RepoOwner,
+				},
+			},
+			Schedule: schedule.Cron,
+		},
+		GitHub: repoCfg.GitHub,
+	}
+}
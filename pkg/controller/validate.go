@@ -1,13 +1,14 @@
 package controller
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
 
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 	"github.com/gha-trigger/gha-trigger/pkg/github"
 	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
 	"github.com/gha-trigger/gha-trigger/pkg/util"
 	"github.com/suzuki-shunsuke/zap-error/logerr"
 	"go.uber.org/zap"
@@ -22,7 +23,14 @@ var (
 	errHeaderXHubEventIsRequired                        = util.WithWarn(errors.New("header X-HUB-EVENT is required"))
 )
 
-func (ctrl *Controller) validate(logger *zap.Logger, req *domain.Request) (*githubapp.GitHubApp, *domain.Event, error) {
+func (ctrl *Controller) validate(ctx context.Context, logger *zap.Logger, req *domain.Request) (*githubapp.GitHubApp, *domain.Event, error) {
+	ctx, span := tracing.Start(ctx, "controller.validate")
+	ghApp, ev, err := ctrl.validateRequest(ctx, logger, req)
+	tracing.End(span, err)
+	return ghApp, ev, err
+}
+
+func (ctrl *Controller) validateRequest(ctx context.Context, logger *zap.Logger, req *domain.Request) (*githubapp.GitHubApp, *domain.Event, error) {
 	headers := req.Params.Headers
 	bodyStr := req.Body
 	appIDS, ok := headers["X-GITHUB-HOOK-INSTALLATION-TARGET-ID"]
@@ -46,6 +54,7 @@ func (ctrl *Controller) validate(logger *zap.Logger, req *domain.Request) (*gith
 	bodyB := []byte(bodyStr)
 	if err := github.ValidateSignature(sig, bodyB, []byte(ghApp.WebhookSecret)); err != nil {
 		logger.Warn("validate the webhook signature", zap.Error(err))
+		metrics.RecordSignatureFailure(ctx, appID)
 		return nil, nil, errSignatureInvalid
 	}
 
@@ -54,23 +63,12 @@ func (ctrl *Controller) validate(logger *zap.Logger, req *domain.Request) (*gith
 		return nil, nil, errHeaderXHubEventIsRequired
 	}
 
-	raw := map[string]interface{}{}
-	if err := json.Unmarshal(bodyB, &raw); err != nil {
-		logger.Warn("parse a webhook payload", zap.Error(err))
-		return nil, nil, fmt.Errorf("parse a webhook payload: %w", err)
-	}
-
-	payload := &domain.Payload{}
-	if err := json.Unmarshal(bodyB, payload); err != nil {
+	ev, err := domain.ParseEvent(evType, bodyB)
+	if err != nil {
 		logger.Warn("parse a webhook payload", zap.Error(err))
-		return nil, nil, fmt.Errorf("parse a webhook payload: %w", err)
+		return nil, nil, err
 	}
+	ev.Request = req
 
-	return ghApp, &domain.Event{
-		Raw:     raw,
-		Type:    evType,
-		Request: req,
-		GitHub:  ghApp.Client,
-		Payload: payload,
-	}, nil
+	return ghApp, ev, nil
 }
@@ -34,9 +34,18 @@ type Payload struct {
 	Repo        *github.Repository   `json:"repository"`
 	PullRequest *github.PullRequest  `json:"pull_request"`
 	Ref         string               `json:"ref"`
+	Before      string               `json:"before"`
+	After       string               `json:"after"`
+	Forced      bool                 `json:"forced"`
 	Action      string               `json:"action"`
 	Deleted     bool                 `json:"deleted"`
 	HeadCommit  *github.HeadCommit   `json:"head_commit"`
 	Comment     *github.IssueComment `json:"comment"`
 	Issue       *github.Issue        `json:"issue"`
+	MergeGroup  *github.MergeGroup   `json:"merge_group"`
+	Sender      *github.User         `json:"sender"`
+	// Installation is the installation of the GitHub App which sent the webhook
+	Installation *github.Installation `json:"installation"`
+	// schedule event
+	Schedule string `json:"schedule"`
 }
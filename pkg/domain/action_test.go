@@ -11,6 +11,40 @@ import (
 	"github.com/google/go-cmp/cmp"
 )
 
+type githubInEvent struct {
+	files     []*github.CommitFile
+	treeFiles []*github.CommitFile
+	truncated bool
+	lowRate   bool
+	commit    *github.RepositoryCommit
+	resp      *github.Response
+	err       error
+}
+
+func (gh *githubInEvent) ListPRFiles(ctx context.Context, param *github.ParamsListPRFiles) ([]*github.CommitFile, *github.Response, error) {
+	return gh.files, gh.resp, gh.err
+}
+
+func (gh *githubInEvent) GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error) {
+	return gh.commit, gh.resp, gh.err
+}
+
+func (gh *githubInEvent) CompareCommitFiles(ctx context.Context, owner, repo, base, head string) ([]*github.CommitFile, *github.Response, error) {
+	return gh.files, gh.resp, gh.err
+}
+
+func (gh *githubInEvent) DiffTrees(ctx context.Context, owner, repo, base, head string) ([]*github.CommitFile, bool, error) {
+	return gh.treeFiles, gh.truncated, gh.err
+}
+
+func (gh *githubInEvent) GetMergeBase(ctx context.Context, owner, repo, base, head string) (string, *github.Response, error) {
+	return "zzz", gh.resp, gh.err
+}
+
+func (gh *githubInEvent) IsRateLimitLow() bool {
+	return gh.lowRate
+}
+
 func TestEvent_GetChangedFiles(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -18,6 +52,7 @@ func TestEvent_GetChangedFiles(t *testing.T) {
 		ev      *domain.Event
 		wantErr bool
 		exp     []string
+		unknown bool
 	}{
 		{
 			name: "already set",
@@ -31,6 +66,214 @@ func TestEvent_GetChangedFiles(t *testing.T) {
 			},
 			exp: []string{"foo"},
 		},
+		{
+			name: "merge_group",
+			ev: &domain.Event{
+				Type: "merge_group",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{},
+					MergeGroup: &github.MergeGroup{
+						BaseSHA: util.StrP("xxx"),
+						HeadSHA: util.StrP("yyy"),
+					},
+				},
+				GitHub: &githubInEvent{
+					files: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+					},
+				},
+			},
+			exp: []string{"foo"},
+		},
+		{
+			name: "pull_request files are truncated",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{},
+					PullRequest: &github.PullRequest{
+						ChangedFiles: util.IntP(3),
+						Base: &github.PullRequestBranch{
+							SHA: util.StrP("xxx"),
+						},
+						Head: &github.PullRequestBranch{
+							SHA: util.StrP("yyy"),
+						},
+					},
+				},
+				GitHub: &githubInEvent{
+					files: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+					},
+					treeFiles: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+						{
+							Filename: util.StrP("bar"),
+						},
+						{
+							Filename: util.StrP("zoo"),
+						},
+					},
+				},
+			},
+			exp: []string{"bar", "foo", "zoo"},
+		},
+		{
+			// the API returns at most MaxPRFiles files, so the list is truncated
+			name: "pull_request files are more than MaxPRFiles",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{},
+					PullRequest: &github.PullRequest{
+						ChangedFiles: util.IntP(github.MaxPRFiles + 1),
+						Base: &github.PullRequestBranch{
+							SHA: util.StrP("xxx"),
+						},
+						Head: &github.PullRequestBranch{
+							SHA: util.StrP("yyy"),
+						},
+					},
+				},
+				GitHub: &githubInEvent{
+					files: make([]*github.CommitFile, github.MaxPRFiles),
+					treeFiles: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+					},
+				},
+			},
+			exp: []string{"foo"},
+		},
+		{
+			name: "pull_request files are unknown",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{},
+					PullRequest: &github.PullRequest{
+						ChangedFiles: util.IntP(3),
+						Base: &github.PullRequestBranch{
+							SHA: util.StrP("xxx"),
+						},
+						Head: &github.PullRequestBranch{
+							SHA: util.StrP("yyy"),
+						},
+					},
+				},
+				GitHub: &githubInEvent{
+					files: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+					},
+					truncated: true,
+				},
+			},
+			exp:     []string{"foo"},
+			unknown: true,
+		},
+		{
+			name: "pull_request trees aren't compared because the rate limit is low",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{},
+					PullRequest: &github.PullRequest{
+						ChangedFiles: util.IntP(3),
+						Base: &github.PullRequestBranch{
+							SHA: util.StrP("xxx"),
+						},
+						Head: &github.PullRequestBranch{
+							SHA: util.StrP("yyy"),
+						},
+					},
+				},
+				GitHub: &githubInEvent{
+					files: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+					},
+					treeFiles: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+						{
+							Filename: util.StrP("bar"),
+						},
+						{
+							Filename: util.StrP("zoo"),
+						},
+					},
+					lowRate: true,
+				},
+			},
+			exp:     []string{"foo"},
+			unknown: true,
+		},
+		{
+			name: "push",
+			ev: &domain.Event{
+				Type: "push",
+				Payload: &domain.Payload{
+					Repo:   &github.Repository{},
+					Ref:    "refs/heads/feature",
+					Before: "xxx",
+					After:  "yyy",
+				},
+				GitHub: &githubInEvent{
+					files: []*github.CommitFile{
+						{
+							Filename: util.StrP("foo"),
+						},
+						{
+							Filename: util.StrP("bar"),
+						},
+					},
+				},
+			},
+			exp: []string{"bar", "foo"},
+		},
+		{
+			name: "push a new default branch",
+			ev: &domain.Event{
+				Type: "push",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						DefaultBranch: util.StrP("main"),
+					},
+					Ref:    "refs/heads/main",
+					Before: "0000000000000000000000000000000000000000",
+					After:  "yyy",
+				},
+				GitHub: &githubInEvent{
+					commit: &github.RepositoryCommit{
+						Files: []*github.CommitFile{
+							{
+								Filename: util.StrP("foo"),
+							},
+						},
+					},
+				},
+			},
+			exp: []string{"foo"},
+		},
+		{
+			name: "merge_group without merge_group",
+			ev: &domain.Event{
+				Type:    "merge_group",
+				Payload: &domain.Payload{},
+			},
+			wantErr: true,
+		},
 	}
 	ctx := context.Background()
 	for _, tt := range tests {
@@ -51,6 +294,9 @@ func TestEvent_GetChangedFiles(t *testing.T) {
 			if diff := cmp.Diff(files, tt.exp); diff != "" {
 				t.Fatal(diff)
 			}
+			if tt.ev.ChangedFilesUnknown != tt.unknown {
+				t.Fatalf("ChangedFilesUnknown: wanted %v, got %v", tt.unknown, tt.ev.ChangedFilesUnknown)
+			}
 		})
 	}
 }
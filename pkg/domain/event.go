@@ -2,10 +2,12 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
 )
 
 type Event struct {
@@ -13,14 +15,50 @@ type Event struct {
 	Payload         *Payload
 	ChangedFiles    []string
 	ChangedFileObjs []*github.CommitFile
-	Type            string
-	Request         *Request
-	GitHub          GitHubInEvent
+	// ChangedFilesUnknown is true if the full list of changed files can't be got
+	// because of the limit of GitHub API. ChangedFiles is partial then.
+	ChangedFilesUnknown bool
+	Type                string
+	Request             *Request
+	GitHub              GitHubInEvent
+}
+
+// ParseEvent parses a webhook payload.
+// GitHub and Request aren't set.
+func ParseEvent(evType string, body []byte) (*Event, error) {
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse a webhook payload: %w", err)
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, fmt.Errorf("parse a webhook payload: %w", err)
+	}
+
+	return &Event{
+		Raw:     raw,
+		Type:    evType,
+		Payload: payload,
+	}, nil
+}
+
+// DeliveryID returns the header X-GitHub-Delivery of the webhook request.
+// It's empty if the event isn't a webhook such as a schedule.
+func (ev *Event) DeliveryID() string {
+	if ev.Request == nil || ev.Request.Params == nil {
+		return ""
+	}
+	return ev.Request.Params.Headers["X-GITHUB-DELIVERY"]
 }
 
 type GitHubInEvent interface {
 	ListPRFiles(ctx context.Context, param *github.ParamsListPRFiles) ([]*github.CommitFile, *github.Response, error)
 	GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error)
+	CompareCommitFiles(ctx context.Context, owner, repo, base, head string) ([]*github.CommitFile, *github.Response, error)
+	DiffTrees(ctx context.Context, owner, repo, base, head string) ([]*github.CommitFile, bool, error)
+	GetMergeBase(ctx context.Context, owner, repo, base, head string) (string, *github.Response, error)
+	IsRateLimitLow() bool
 }
 
 func getChangedFiles(files []*github.CommitFile) []string {
@@ -41,6 +79,13 @@ func getChangedFiles(files []*github.CommitFile) []string {
 }
 
 func (ev *Event) GetChangedFiles(ctx context.Context) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "Event.GetChangedFiles")
+	files, err := ev.listChangedFiles(ctx)
+	tracing.End(span, err)
+	return files, err
+}
+
+func (ev *Event) listChangedFiles(ctx context.Context) ([]string, error) {
 	if ev.ChangedFileObjs != nil {
 		return ev.ChangedFiles, nil
 	}
@@ -59,16 +104,72 @@ func (ev *Event) GetChangedFiles(ctx context.Context) ([]string, error) {
 		if err != nil {
 			return nil, fmt.Errorf("list pull request files: %w", err)
 		}
+		if len(files) < pr.GetChangedFiles() {
+			files, err = ev.diffTrees(ctx, files, pr.GetBase().GetSHA(), pr.GetHead().GetSHA(), true)
+			if err != nil {
+				return nil, err
+			}
+		}
 		ev.ChangedFileObjs = files
 		ev.ChangedFiles = getChangedFiles(files)
 	case "push":
-		commit, _, err := ev.GitHub.GetCommit(ctx, ev.Payload.Repo.GetOwner().GetLogin(), ev.Payload.Repo.GetName(), ev.Payload.HeadCommit.GetID())
+		files, err := ev.listPushFiles(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("list commit files: %w", err)
+			return nil, err
+		}
+		ev.ChangedFileObjs = files
+		ev.ChangedFiles = getChangedFiles(files)
+	case "merge_group":
+		if ev.Payload.MergeGroup == nil {
+			return nil, errors.New("body must have a merge group")
 		}
-		ev.ChangedFileObjs = commit.Files
-		ev.ChangedFiles = getChangedFiles(commit.Files)
+		mg := ev.Payload.MergeGroup
+		files, _, err := ev.GitHub.CompareCommitFiles(ctx, ev.Payload.Repo.GetOwner().GetLogin(), ev.Payload.Repo.GetName(), mg.GetBaseSHA(), mg.GetHeadSHA())
+		if err != nil {
+			return nil, fmt.Errorf("compare the merge group's base and head commits: %w", err)
+		}
+		if len(files) >= github.MaxCompareFiles {
+			files, err = ev.diffTrees(ctx, files, mg.GetBaseSHA(), mg.GetHeadSHA(), false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ev.ChangedFileObjs = files
+		ev.ChangedFiles = getChangedFiles(files)
 	default:
 	}
 	return ev.ChangedFiles, nil
 }
+
+// diffTrees is a fallback when the list of changed files may be truncated by the limit of REST API.
+// If the full list can't be got either or the rate limit is low, ChangedFilesUnknown is set and partial files are returned.
+func (ev *Event) diffTrees(ctx context.Context, partial []*github.CommitFile, base, head string, mergeBase bool) ([]*github.CommitFile, error) {
+	owner := ev.Payload.Repo.GetOwner().GetLogin()
+	repoName := ev.Payload.Repo.GetName()
+	if base == "" || head == "" {
+		ev.ChangedFilesUnknown = true
+		return partial, nil
+	}
+	if ev.GitHub.IsRateLimitLow() {
+		// comparing trees calls API many times, so it's skipped to keep the rate limit for dispatching workflows.
+		// The policy changed_files_unknown is applied instead.
+		ev.ChangedFilesUnknown = true
+		return partial, nil
+	}
+	if mergeBase {
+		sha, _, err := ev.GitHub.GetMergeBase(ctx, owner, repoName, base, head)
+		if err != nil {
+			return nil, fmt.Errorf("get a merge base commit: %w", err)
+		}
+		base = sha
+	}
+	files, truncated, err := ev.GitHub.DiffTrees(ctx, owner, repoName, base, head)
+	if err != nil {
+		return nil, fmt.Errorf("compare git trees: %w", err)
+	}
+	if truncated {
+		ev.ChangedFilesUnknown = true
+		return partial, nil
+	}
+	return files, nil
+}
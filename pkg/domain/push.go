@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+)
+
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// listPushFiles returns files changed by all commits of a push event.
+// Files are listed by comparing before and after.
+//
+// - New branch (before is zero): compare with the default branch
+// - Force push: compare before and after by the merge base. If it fails, only the head commit is checked
+func (ev *Event) listPushFiles(ctx context.Context) ([]*github.CommitFile, error) {
+	payload := ev.Payload
+	owner := payload.Repo.GetOwner().GetLogin()
+	repoName := payload.Repo.GetName()
+	if payload.Deleted || strings.Trim(payload.After, "0") == "" {
+		return []*github.CommitFile{}, nil
+	}
+	if payload.Before == "" {
+		return ev.listHeadCommitFiles(ctx)
+	}
+	base := payload.Before
+	if base == zeroSHA {
+		base = payload.Repo.GetDefaultBranch()
+		if base == "" || base == strings.TrimPrefix(payload.Ref, "refs/heads/") {
+			return ev.listHeadCommitFiles(ctx)
+		}
+	}
+	files, _, err := ev.GitHub.CompareCommitFiles(ctx, owner, repoName, base, payload.After)
+	if err != nil {
+		if payload.Forced {
+			// before may have been garbage collected
+			return ev.listHeadCommitFiles(ctx)
+		}
+		return nil, fmt.Errorf("compare commits: %w", err)
+	}
+	if len(files) >= github.MaxCompareFiles {
+		return ev.diffTrees(ctx, files, base, payload.After, true)
+	}
+	return files, nil
+}
+
+func (ev *Event) listHeadCommitFiles(ctx context.Context) ([]*github.CommitFile, error) {
+	commit, _, err := ev.GitHub.GetCommit(ctx, ev.Payload.Repo.GetOwner().GetLogin(), ev.Payload.Repo.GetName(), ev.Payload.HeadCommit.GetID())
+	if err != nil {
+		return nil, fmt.Errorf("list commit files: %w", err)
+	}
+	if len(commit.Files) >= github.MaxCommitFiles {
+		if len(commit.Parents) != 1 {
+			// root commits and merge commits
+			ev.ChangedFilesUnknown = true
+			return commit.Files, nil
+		}
+		return ev.diffTrees(ctx, commit.Files, commit.Parents[0].GetSHA(), commit.GetSHA(), false)
+	}
+	return commit.Files, nil
+}
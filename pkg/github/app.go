@@ -0,0 +1,235 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
+)
+
+// App is a GitHub App.
+// Clients are created per installation and cached, so installation access tokens are reused.
+// The installation of Org or User is looked up at the first API call instead of startup.
+type App struct {
+	param *ParamNewApp
+	atr   *ghinstallation.AppsTransport
+	apps  AppsService
+
+	// retryInterval is the initial interval to retry finding the installation
+	retryInterval time.Duration
+
+	mu            sync.Mutex
+	installations map[int64]*installation
+	client        *Client
+}
+
+type installation struct {
+	transport http.RoundTripper
+	tracker   *rateLimitTracker
+	client    *Client
+}
+
+type AppsService interface {
+	FindOrganizationInstallation(ctx context.Context, org string) (*Installation, *Response, error)
+	FindUserInstallation(ctx context.Context, user string) (*Installation, *Response, error)
+}
+
+var errInstallationRequired = errors.New("either installation id, org, or user is required")
+
+// NewApp creates a GitHub App. GitHub API isn't called.
+func NewApp(param *ParamNewApp) (*App, error) {
+	if param.InstallationID == 0 && param.Org == "" && param.User == "" {
+		return nil, errInstallationRequired
+	}
+	httpTransport, err := newHTTPTransport(param.CABundles)
+	if err != nil {
+		return nil, err
+	}
+	apiBaseURL, err := getAPIBaseURL(param)
+	if err != nil {
+		return nil, err
+	}
+	// record the latency of API calls including the ones to create installation tokens
+	base := metrics.NewTransport(tracing.NewTransport(httpTransport), param.AppID)
+	atr, err := ghinstallation.NewAppsTransport(base, param.AppID, []byte(param.KeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("create a transport with private key: %w", err)
+	}
+	atr.BaseURL = apiBaseURL
+	aClient, err := newV3Client(param, &http.Client{Transport: withCache(atr, param.Cache, fmt.Sprintf("app/%d", param.AppID))})
+	if err != nil {
+		return nil, err
+	}
+	app := &App{
+		param:         param,
+		atr:           atr,
+		apps:          aClient.Apps,
+		installations: map[int64]*installation{},
+		retryInterval: findInstallationInterval,
+	}
+	if param.InstallationID != 0 {
+		client, err := app.InstallationClient(param.InstallationID)
+		if err != nil {
+			return nil, err
+		}
+		app.client = client
+		return app, nil
+	}
+	lazy := &lazyTransport{app: app}
+	gh, err := newV3Client(param, &http.Client{Transport: lazy})
+	if err != nil {
+		return nil, err
+	}
+	app.client = New(gh)
+	app.client.rateLimit = lazy
+	return app, nil
+}
+
+// Client returns the client of the installation configured by InstallationID, Org, or User.
+func (app *App) Client() *Client {
+	return app.client
+}
+
+// InstallationClient returns the client of the installation.
+// One GitHub App can be installed in multiple organizations, and the installation is given by webhooks.
+func (app *App) InstallationClient(installationID int64) (*Client, error) {
+	inst, err := app.getInstallation(installationID)
+	if err != nil {
+		return nil, err
+	}
+	return inst.client, nil
+}
+
+func (app *App) getInstallation(installationID int64) (*installation, error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if inst, ok := app.installations[installationID]; ok {
+		return inst, nil
+	}
+	tracker := &rateLimitTracker{
+		appID:          app.param.AppID,
+		installationID: installationID,
+	}
+	tr := withCache(&rateLimitTransport{
+		base:    ghinstallation.NewFromAppsTransport(app.atr, installationID),
+		tracker: tracker,
+	}, app.param.Cache, fmt.Sprintf("app/%d/installation/%d", app.param.AppID, installationID))
+	gh, err := newV3Client(app.param, &http.Client{Transport: tr})
+	if err != nil {
+		return nil, err
+	}
+	client := New(gh)
+	client.rateLimit = tracker
+	inst := &installation{
+		transport: tr,
+		tracker:   tracker,
+		client:    client,
+	}
+	app.installations[installationID] = inst
+	return inst, nil
+}
+
+// withCache wraps the transport with the cache if the cache is set.
+// The cache is shared by GitHub Apps and installations, so entries are separated by scope.
+// Cached requests are conditional, so metrics record 304 responses as they are.
+func withCache(tr http.RoundTripper, cache Cache, scope string) http.RoundTripper {
+	if cache == nil {
+		return tr
+	}
+	return NewCacheTransport(tr, cache, scope)
+}
+
+const (
+	findInstallationAttempts = 3
+	findInstallationInterval = time.Second
+)
+
+// findInstallation finds the installation of Org or User.
+// It's retried because the startup shouldn't fail by a temporary failure of GitHub.
+func (app *App) findInstallation(ctx context.Context) (int64, error) {
+	interval := app.retryInterval
+	var err error
+	for i := 0; i < findInstallationAttempts; i++ {
+		if i != 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return 0, ctx.Err() //nolint:wrapcheck
+			case <-timer.C:
+			}
+			interval *= 2
+		}
+		var inst *Installation
+		var resp *Response
+		if app.param.Org != "" {
+			inst, resp, err = app.apps.FindOrganizationInstallation(ctx, app.param.Org)
+		} else {
+			inst, resp, err = app.apps.FindUserInstallation(ctx, app.param.User)
+		}
+		if err == nil {
+			return inst.GetID(), nil
+		}
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// the app isn't installed, so it isn't retried
+			break
+		}
+	}
+	return 0, fmt.Errorf("find the installation of the GitHub App: %w", err)
+}
+
+// lazyTransport finds the installation at the first request.
+// If it fails, it's retried at the next request.
+type lazyTransport struct {
+	app  *App
+	mu   sync.Mutex
+	inst *installation
+}
+
+func (t *lazyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr, err := t.get(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	return tr.RoundTrip(req) //nolint:wrapcheck
+}
+
+func (t *lazyTransport) get(ctx context.Context) (http.RoundTripper, error) {
+	t.mu.Lock()
+	inst := t.inst
+	t.mu.Unlock()
+	if inst != nil {
+		return inst.transport, nil
+	}
+	// the lock isn't held while retrying so that other requests aren't blocked.
+	// The installation may be found concurrently, but getInstallation returns the same installation.
+	installationID, err := t.app.findInstallation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inst, err = t.app.getInstallation(installationID)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.inst = inst
+	t.mu.Unlock()
+	return inst.transport, nil
+}
+
+// RateLimit returns the rate limit of the resolved installation.
+func (t *lazyTransport) RateLimit() *RateLimit {
+	t.mu.Lock()
+	inst := t.inst
+	t.mu.Unlock()
+	if inst == nil {
+		return nil
+	}
+	return inst.tracker.RateLimit()
+}
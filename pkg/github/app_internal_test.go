@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048) //nolint:gomnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+func TestApp_Client(t *testing.T) { //nolint:funlen
+	t.Parallel()
+	keyFile := newTestKey(t)
+	tests := []struct {
+		name               string
+		findStatusCodes    []int
+		wantErr            bool
+		expFindInstallCall int32
+	}{
+		{
+			name:               "normal",
+			findStatusCodes:    []int{http.StatusOK},
+			expFindInstallCall: 1,
+		},
+		{
+			name:               "retry",
+			findStatusCodes:    []int{http.StatusInternalServerError, http.StatusOK},
+			expFindInstallCall: 2,
+		},
+		{
+			name:               "not installed",
+			findStatusCodes:    []int{http.StatusNotFound, http.StatusOK},
+			wantErr:            true,
+			expFindInstallCall: 1,
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var findCalls int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v3/orgs/gha-trigger/installation", func(w http.ResponseWriter, r *http.Request) {
+				i := atomic.AddInt32(&findCalls, 1)
+				if code := tt.findStatusCodes[i-1]; code != http.StatusOK {
+					w.WriteHeader(code)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": 10}) //nolint:errcheck
+			})
+			mux.HandleFunc("/api/v3/app/installations/10/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+					"token":      "xxx",
+					"expires_at": time.Now().Add(time.Hour),
+				})
+			})
+			mux.HandleFunc("/api/v3/repos/gha-trigger/example", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]interface{}{"name": "example"}) //nolint:errcheck
+			})
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			app, err := NewApp(&ParamNewApp{
+				AppID:   1,
+				KeyFile: keyFile,
+				Org:     "gha-trigger",
+				BaseURL: srv.URL,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			app.retryInterval = time.Millisecond
+			if n := atomic.LoadInt32(&findCalls); n != 0 {
+				t.Fatalf("the installation must not be found at startup: %d", n)
+			}
+			repo, _, err := app.Client().GetRepo(ctx, "gha-trigger", "example")
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+				if n := atomic.LoadInt32(&findCalls); n != tt.expFindInstallCall {
+					t.Fatalf("FindOrganizationInstallation should be called %d times: %d", tt.expFindInstallCall, n)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if repo.GetName() != "example" {
+				t.Fatalf("wanted example, got %s", repo.GetName())
+			}
+			// the installation is cached
+			if _, _, err := app.Client().GetRepo(ctx, "gha-trigger", "example"); err != nil {
+				t.Fatal(err)
+			}
+			if n := atomic.LoadInt32(&findCalls); n != tt.expFindInstallCall {
+				t.Fatalf("FindOrganizationInstallation should be called %d times: %d", tt.expFindInstallCall, n)
+			}
+			client, err := app.InstallationClient(10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client2, err := app.InstallationClient(10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client != client2 {
+				t.Fatal("the installation client must be cached")
+			}
+		})
+	}
+}
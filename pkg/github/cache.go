@@ -0,0 +1,213 @@
+package github
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CachedResponse is a response cached by CacheTransport.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Cache is the backend of CacheTransport.
+// Get returns nil if the response isn't cached.
+type Cache interface {
+	Get(ctx context.Context, key string) (*CachedResponse, error)
+	Set(ctx context.Context, key string, resp *CachedResponse) error
+}
+
+// maxCachedBodySize is the max size of the cached response body.
+// Large responses such as files of big pull requests aren't cached to limit the memory usage.
+const maxCachedBodySize = 1 << 20
+
+// CacheTransport sends conditional requests with ETag and Last-Modified of cached responses.
+// If GitHub returns 304 Not Modified, the cached response is returned.
+// Conditional requests returning 304 don't count against the rate limit.
+type CacheTransport struct {
+	base  http.RoundTripper
+	cache Cache
+	// scope separates entries of credentials sharing the cache, e.g. app/1/installation/2
+	scope string
+}
+
+func NewCacheTransport(base http.RoundTripper, cache Cache, scope string) *CacheTransport {
+	return &CacheTransport{
+		base:  base,
+		cache: cache,
+		scope: scope,
+	}
+}
+
+// cacheKey returns the key of the request.
+// Authorization isn't included because installation tokens are rotated, so the key is scoped by the credential instead.
+// Otherwise a response cached by an installation could be returned to another installation which isn't permitted to read it,
+// because GitHub may return 304 to the conditional request without checking the permission of the resource.
+func (tr *CacheTransport) cacheKey(req *http.Request) string {
+	return tr.scope + " " + req.URL.String() + " " + req.Header.Get("Accept")
+}
+
+func (tr *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return tr.base.RoundTrip(req) //nolint:wrapcheck
+	}
+	ctx := req.Context()
+	key := tr.cacheKey(req)
+	cached, err := tr.cache.Get(ctx, key)
+	if err != nil {
+		// the cache is an optimization, so the request is sent without the cache
+		cached = nil
+	}
+	if cached != nil {
+		req = req.Clone(ctx)
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	resp, err := tr.base.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		return cached.response(req, resp), nil
+	}
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err //nolint:wrapcheck
+	}
+	if len(body) > maxCachedBodySize {
+		// the rest of the body is read by the caller
+		resp.Body = &readCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			Closer: resp.Body,
+		}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	tr.cache.Set(ctx, key, &CachedResponse{ //nolint:errcheck
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	})
+	return resp, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// response returns the cached response.
+// Headers of the rate limit are taken from the 304 response so that the latest rate limit is tracked.
+func (cached *CachedResponse) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := cached.Header.Clone()
+	for k, v := range notModified.Header {
+		if strings.HasPrefix(k, "X-Ratelimit-") {
+			header[k] = v
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.StatusCode, http.StatusText(cached.StatusCode)),
+		StatusCode:    cached.StatusCode,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
+
+// MemoryCache is an in-memory Cache.
+// If the total size of entries exceeds maxBytes, least recently used entries are evicted.
+type MemoryCache struct {
+	maxBytes int64
+	mu       sync.Mutex
+	size     int64
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+type memoryCacheEntry struct {
+	key  string
+	resp *CachedResponse
+	size int64
+}
+
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxBytes: maxBytes,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+// entrySize returns the approximate memory usage of the entry.
+func entrySize(key string, resp *CachedResponse) int64 {
+	size := len(key) + len(resp.Body)
+	for k, vs := range resp.Header {
+		size += len(k)
+		for _, v := range vs {
+			size += len(v)
+		}
+	}
+	return int64(size)
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).resp, nil //nolint:forcetypeassert
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, resp *CachedResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	size := entrySize(key, resp)
+	if size > c.maxBytes {
+		// the entry would evict all entries
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(&memoryCacheEntry{
+		key:  key,
+		resp: resp,
+		size: size,
+	})
+	c.size += size
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+	return nil
+}
+
+func (c *MemoryCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*memoryCacheEntry) //nolint:forcetypeassert
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
@@ -0,0 +1,115 @@
+package github_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+)
+
+func TestCacheTransport_RoundTrip(t *testing.T) {
+	t.Parallel()
+	notModified := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == `"xxx"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"xxx"`)
+		w.Write([]byte(`{"number": 1}`)) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	cache := github.NewMemoryCache(1 << 20)
+	ctx := context.Background()
+	tests := []struct {
+		scope       string
+		notModified int
+	}{
+		{
+			scope: "app/1/installation/2",
+		},
+		{
+			scope:       "app/1/installation/2",
+			notModified: 1,
+		},
+		{
+			// the response cached by the other installation isn't used
+			scope:       "app/1/installation/3",
+			notModified: 1,
+		},
+	}
+	for _, tt := range tests {
+		client := &http.Client{
+			Transport: github.NewCacheTransport(http.DefaultTransport, cache, tt.scope),
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/repos/gha-trigger/example/pulls/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status code should be 200: %d", resp.StatusCode)
+		}
+		if string(b) != `{"number": 1}` {
+			t.Fatalf("body is unexpected: %s", string(b))
+		}
+		if notModified != tt.notModified {
+			t.Fatalf("the number of conditional requests of %s: wanted %d, got %d", tt.scope, tt.notModified, notModified)
+		}
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// each entry is 11 bytes, so two entries are cached
+	cache := github.NewMemoryCache(25)
+	newResp := func() *github.CachedResponse {
+		return &github.CachedResponse{StatusCode: http.StatusOK, Body: []byte("0123456789")}
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(ctx, key, newResp()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a is used recently, so b is evicted
+	if resp, _ := cache.Get(ctx, "a"); resp == nil {
+		t.Fatal("a must be cached")
+	}
+	if err := cache.Set(ctx, "c", newResp()); err != nil {
+		t.Fatal(err)
+	}
+	if resp, _ := cache.Get(ctx, "b"); resp != nil {
+		t.Fatal("b must be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if resp, _ := cache.Get(ctx, key); resp == nil {
+			t.Fatalf("%s must be cached", key)
+		}
+	}
+	// the entry larger than the cache isn't cached and doesn't evict others
+	if err := cache.Set(ctx, "d", &github.CachedResponse{StatusCode: http.StatusOK, Body: make([]byte, 30)}); err != nil {
+		t.Fatal(err)
+	}
+	if resp, _ := cache.Get(ctx, "d"); resp != nil {
+		t.Fatal("d must not be cached")
+	}
+	for _, key := range []string{"a", "c"} {
+		if resp, _ := cache.Get(ctx, key); resp == nil {
+			t.Fatalf("%s must be cached", key)
+		}
+	}
+}
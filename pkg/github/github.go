@@ -1,18 +1,24 @@
 package github
 
 import (
-	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
-	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v52/github"
 )
 
 type Client struct {
 	action ActionsService
 	pr     PullRequestsService
 	repo   RepositoriesService
+	git    GitService
+	issue  IssuesService
+	// rateLimit is nil if the client isn't created by App
+	rateLimit rateLimitSource
 }
 
 func New(gh *V3Client) *Client {
@@ -20,6 +26,8 @@ func New(gh *V3Client) *Client {
 		pr:     gh.PullRequests,
 		action: gh.Actions,
 		repo:   gh.Repositories,
+		git:    gh.Git,
+		issue:  gh.Issues,
 	}
 }
 
@@ -33,44 +41,63 @@ type ParamNewApp struct {
 	InstallationID int64
 	Org            string
 	User           string
+	// BaseURL and UploadURL are URLs of GitHub Enterprise Server.
+	// If BaseURL is empty, github.com is used. If UploadURL is empty, it's derived from BaseURL.
+	BaseURL   string
+	UploadURL string
+	// CABundles are PEM encoded certificates trusted in addition to the system's ones
+	CABundles [][]byte
+	// If Cache is set, GET requests are sent conditionally with cached responses
+	Cache Cache
 }
 
-func newTransport(ctx context.Context, param *ParamNewApp) (http.RoundTripper, error) {
-	if param.InstallationID != 0 {
-		return ghinstallation.New(http.DefaultTransport, param.AppID, param.InstallationID, []byte(param.KeyFile))
+// newV3Client creates a client of github.com or GitHub Enterprise Server.
+// If httpClient is nil, http.DefaultClient is used.
+func newV3Client(param *ParamNewApp, httpClient *http.Client) (*V3Client, error) {
+	if param.BaseURL == "" {
+		return NewV3Client(httpClient), nil
 	}
-	if param.Org != "" {
-		atr, err := ghinstallation.NewAppsTransport(http.DefaultTransport, param.AppID, []byte(param.KeyFile))
-		if err != nil {
-			return nil, err
-		}
-		aClient := NewV3Client(&http.Client{Transport: atr})
-		inst, _, err := aClient.Apps.FindOrganizationInstallation(ctx, param.Org)
-		if err != nil {
-			return nil, err
-		}
-		return ghinstallation.NewFromAppsTransport(atr, inst.GetID()), nil
+	uploadURL := param.UploadURL
+	if uploadURL == "" {
+		// go-github appends api/uploads/ to the host
+		uploadURL = strings.TrimSuffix(strings.TrimSuffix(param.BaseURL, "/"), "/api/v3")
 	}
-	if param.User != "" {
-		atr, err := ghinstallation.NewAppsTransport(http.DefaultTransport, param.AppID, []byte(param.KeyFile))
-		if err != nil {
-			return nil, err
-		}
-		aClient := NewV3Client(&http.Client{Transport: atr})
-		inst, _, err := aClient.Apps.FindUserInstallation(ctx, param.User)
-		if err != nil {
-			return nil, err
-		}
-		return ghinstallation.NewFromAppsTransport(atr, inst.GetID()), nil
+	gh, err := github.NewEnterpriseClient(param.BaseURL, uploadURL, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("create a GitHub Enterprise Server client: %w", err)
+	}
+	return gh, nil
+}
+
+// getAPIBaseURL returns the API base URL passed to ghinstallation, e.g. https://ghes.example.com/api/v3
+func getAPIBaseURL(param *ParamNewApp) (string, error) {
+	gh, err := newV3Client(param, nil)
+	if err != nil {
+		return "", err
 	}
-	return nil, errors.New("either installation id, org, or user is required")
+	return strings.TrimSuffix(gh.BaseURL.String(), "/"), nil
 }
 
-func NewApp(ctx context.Context, param *ParamNewApp) (*Client, error) {
-	itr, err := newTransport(ctx, param)
+// newHTTPTransport returns a transport trusting CA bundles.
+func newHTTPTransport(caBundles [][]byte) (http.RoundTripper, error) {
+	if len(caBundles) == 0 {
+		return http.DefaultTransport, nil
+	}
+	pool, err := x509.SystemCertPool()
 	if err != nil {
-		return nil, fmt.Errorf("create a transport with private key: %w", err)
+		pool = x509.NewCertPool()
 	}
-	gh := NewV3Client(&http.Client{Transport: itr})
-	return New(gh), nil
+	for _, b := range caBundles {
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errNoCertificateInCABundle
+		}
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	tr.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	return tr, nil
 }
+
+var errNoCertificateInCABundle = errors.New("no certificate is found in the CA bundle")
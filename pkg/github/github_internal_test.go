@@ -0,0 +1,74 @@
+package github
+
+import (
+	"testing"
+)
+
+func Test_getAPIBaseURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		param     *ParamNewApp
+		exp       string
+		expUpload string
+	}{
+		{
+			name:      "github.com",
+			param:     &ParamNewApp{},
+			exp:       "https://api.github.com",
+			expUpload: "https://uploads.github.com/",
+		},
+		{
+			name: "GitHub Enterprise Server",
+			param: &ParamNewApp{
+				BaseURL: "https://ghes.example.com",
+			},
+			exp:       "https://ghes.example.com/api/v3",
+			expUpload: "https://ghes.example.com/api/uploads/",
+		},
+		{
+			name: "base url has api/v3",
+			param: &ParamNewApp{
+				BaseURL: "https://ghes.example.com/api/v3/",
+			},
+			exp:       "https://ghes.example.com/api/v3",
+			expUpload: "https://ghes.example.com/api/uploads/",
+		},
+		{
+			name: "upload url",
+			param: &ParamNewApp{
+				BaseURL:   "https://ghes.example.com/api/v3/",
+				UploadURL: "https://uploads.ghes.example.com/",
+			},
+			exp:       "https://ghes.example.com/api/v3",
+			expUpload: "https://uploads.ghes.example.com/api/uploads/",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u, err := getAPIBaseURL(tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u != tt.exp {
+				t.Fatalf("wanted %s, got %s", tt.exp, u)
+			}
+			gh, err := newV3Client(tt.param, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := gh.UploadURL.String(); s != tt.expUpload {
+				t.Fatalf("upload url: wanted %s, got %s", tt.expUpload, s)
+			}
+		})
+	}
+}
+
+func Test_newHTTPTransport(t *testing.T) {
+	t.Parallel()
+	if _, err := newHTTPTransport([][]byte{[]byte("invalid")}); err == nil {
+		t.Fatal("error must be returned if the CA bundle has no certificate")
+	}
+}
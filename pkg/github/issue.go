@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"strings"
+)
+
+type IssuesService interface {
+	CreateComment(ctx context.Context, owner string, repo string, number int, comment *IssueComment) (*IssueComment, *Response, error)
+	EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *IssueComment) (*IssueComment, *Response, error)
+	ListComments(ctx context.Context, owner string, repo string, number int, opts *IssueListCommentsOptions) ([]*IssueComment, *Response, error)
+}
+
+// maxCommentPages is the max number of pages searched for the comment to be updated.
+const maxCommentPages = 10
+
+// UpsertIssueComment updates the comment including marker or creates a comment if it isn't found.
+// marker should be a HTML comment so that it isn't rendered.
+func (client *Client) UpsertIssueComment(ctx context.Context, owner, repo string, number int, marker, body string) (*Response, error) {
+	body = marker + "\n" + body
+	comment, resp, err := client.findIssueComment(ctx, owner, repo, number, marker)
+	if err != nil {
+		return resp, err
+	}
+	if comment == nil {
+		_, resp, err := client.issue.CreateComment(ctx, owner, repo, number, &IssueComment{
+			Body: &body,
+		})
+		return resp, err //nolint:wrapcheck
+	}
+	if comment.GetBody() == body {
+		return resp, nil
+	}
+	_, resp, err = client.issue.EditComment(ctx, owner, repo, comment.GetID(), &IssueComment{
+		Body: &body,
+	})
+	return resp, err //nolint:wrapcheck
+}
+
+func (client *Client) findIssueComment(ctx context.Context, owner, repo string, number int, marker string) (*IssueComment, *Response, error) {
+	opts := &IssueListCommentsOptions{
+		ListOptions: ListOptions{
+			PerPage: maxPerPage,
+		},
+	}
+	var gResp *Response
+	for i := 0; i < maxCommentPages; i++ {
+		comments, resp, err := client.issue.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, resp, err //nolint:wrapcheck
+		}
+		gResp = resp
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.GetBody(), marker) {
+				return comment, resp, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil, gResp, nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type issuesService struct {
+	comments []*IssueComment
+	created  int
+	edited   int
+}
+
+func (svc *issuesService) CreateComment(ctx context.Context, owner string, repo string, number int, comment *IssueComment) (*IssueComment, *Response, error) {
+	svc.created++
+	return comment, &Response{}, nil
+}
+
+func (svc *issuesService) EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *IssueComment) (*IssueComment, *Response, error) {
+	svc.edited++
+	return comment, &Response{}, nil
+}
+
+func (svc *issuesService) ListComments(ctx context.Context, owner string, repo string, number int, opts *IssueListCommentsOptions) ([]*IssueComment, *Response, error) {
+	return svc.comments, &Response{}, nil
+}
+
+func TestClient_UpsertIssueComment(t *testing.T) {
+	t.Parallel()
+	marker := "<!-- test -->"
+	tests := []struct {
+		name       string
+		comments   []*IssueComment
+		expCreated int
+		expEdited  int
+	}{
+		{
+			name: "create",
+			comments: []*IssueComment{
+				{ID: util.Int64P(1), Body: util.StrP("lgtm")},
+			},
+			expCreated: 1,
+		},
+		{
+			name: "edit",
+			comments: []*IssueComment{
+				{ID: util.Int64P(1), Body: util.StrP(marker + "\nold")},
+			},
+			expEdited: 1,
+		},
+		{
+			name: "not changed",
+			comments: []*IssueComment{
+				{ID: util.Int64P(1), Body: util.StrP(marker + "\nnew")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &issuesService{comments: tt.comments}
+			client := &Client{issue: svc}
+			if _, err := client.UpsertIssueComment(context.Background(), "gha-trigger", "example-main", 1, marker, "new"); err != nil {
+				t.Fatal(err)
+			}
+			if svc.created != tt.expCreated {
+				t.Fatalf("created: wanted %d, got %d", tt.expCreated, svc.created)
+			}
+			if svc.edited != tt.expEdited {
+				t.Fatalf("edited: wanted %d, got %d", tt.expEdited, svc.edited)
+			}
+		})
+	}
+}
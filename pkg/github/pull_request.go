@@ -28,6 +28,10 @@ func (client *Client) ListPRFiles(ctx context.Context, param *ParamsListPRFiles)
 		return nil, nil, nil
 	}
 	n := (param.Count / maxPerPage) + 1
+	if maxPages := MaxPRFiles / maxPerPage; n > maxPages {
+		// the API doesn't return files beyond MaxPRFiles
+		n = maxPages
+	}
 	var gResp *Response
 	for i := 1; i <= n; i++ {
 		opts := &ListOptions{
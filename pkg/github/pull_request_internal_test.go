@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"testing"
+)
+
+// pullRequestsService returns total files like the API, which returns at most MaxPRFiles files.
+type pullRequestsService struct {
+	total int
+	pages int
+}
+
+func (svc *pullRequestsService) ListFiles(ctx context.Context, owner, repo string, number int, opts *ListOptions) ([]*CommitFile, *Response, error) {
+	svc.pages++
+	total := svc.total
+	if total > MaxPRFiles {
+		total = MaxPRFiles
+	}
+	n := total - (opts.Page-1)*opts.PerPage
+	if n > opts.PerPage {
+		n = opts.PerPage
+	}
+	if n < 0 {
+		n = 0
+	}
+	return make([]*CommitFile, n), &Response{}, nil
+}
+
+func (svc *pullRequestsService) Get(ctx context.Context, owner, repo string, number int) (*PullRequest, *Response, error) {
+	return nil, nil, nil
+}
+
+func TestClient_ListPRFiles(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		count    int
+		expFiles int
+		expPages int
+	}{
+		{
+			name:     "normal",
+			count:    150,
+			expFiles: 150,
+			expPages: 2,
+		},
+		{
+			// the list is shorter than count, so the caller falls back to comparing git trees
+			name:     "more than MaxPRFiles",
+			count:    5000,
+			expFiles: MaxPRFiles,
+			expPages: MaxPRFiles / maxPerPage,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &pullRequestsService{total: tt.count}
+			client := &Client{pr: svc}
+			files, _, err := client.ListPRFiles(context.Background(), &ParamsListPRFiles{
+				Owner:  "gha-trigger",
+				Repo:   "example-main",
+				Number: 1,
+				Count:  tt.count,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != tt.expFiles {
+				t.Fatalf("wanted %d files, got %d", tt.expFiles, len(files))
+			}
+			if svc.pages != tt.expPages {
+				t.Fatalf("wanted %d pages, got %d", tt.expPages, svc.pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
+)
+
+// RateLimit is the latest rate limit of an installation.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// rateLimitLowRatio is the ratio of the remaining rate limit regarded as low.
+// Non-essential API calls are skipped when the remaining rate limit is below it so that workflows can be dispatched.
+const rateLimitLowRatio = 0.1
+
+// IsLow returns true if the remaining rate limit is below 10% of the limit until the rate limit is reset.
+func (rl *RateLimit) IsLow(now time.Time) bool {
+	if rl == nil || rl.Limit == 0 || !now.Before(rl.Reset) {
+		return false
+	}
+	return float64(rl.Remaining) < float64(rl.Limit)*rateLimitLowRatio
+}
+
+// rateLimitSource returns the latest rate limit.
+// It returns nil if no API has been called yet.
+type rateLimitSource interface {
+	RateLimit() *RateLimit
+}
+
+// rateLimitTracker records the rate limit of an installation from response headers.
+type rateLimitTracker struct {
+	appID          int64
+	installationID int64
+	mu             sync.Mutex
+	rate           *RateLimit
+}
+
+func (t *rateLimitTracker) RateLimit() *RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rate
+}
+
+// update records the rate limit and reports if it becomes low.
+func (t *rateLimitTracker) update(req *http.Request, header http.Header) {
+	rate, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	wasLow := t.rate.IsLow(now)
+	t.rate = rate
+	t.mu.Unlock()
+	metrics.SetRateLimitRemaining(t.appID, t.installationID, int64(rate.Remaining))
+	if !wasLow && rate.IsLow(now) {
+		metrics.RecordRateLimitLow(req.Context(), t.appID, t.installationID)
+	}
+}
+
+func parseRateLimit(header http.Header) (*RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return nil, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return nil, false
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}, true
+}
+
+// rateLimitTransport tracks the rate limit of an installation.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	tracker *rateLimitTracker
+}
+
+func (tr *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := tr.base.RoundTrip(req)
+	if resp != nil {
+		tr.tracker.update(req, resp.Header)
+	}
+	return resp, err //nolint:wrapcheck
+}
+
+// RateLimit returns the latest rate limit of the client's installation.
+// It returns nil if it's unknown.
+func (client *Client) RateLimit() *RateLimit {
+	if client.rateLimit == nil {
+		return nil
+	}
+	return client.rateLimit.RateLimit()
+}
+
+// IsRateLimitLow returns true if the remaining rate limit is low.
+// Callers should skip non-essential API calls then.
+func (client *Client) IsRateLimitLow() bool {
+	return client.RateLimit().IsLow(time.Now())
+}
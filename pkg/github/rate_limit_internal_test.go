@@ -0,0 +1,111 @@
+package github
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseRateLimit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		header http.Header
+		exp    *RateLimit
+		ok     bool
+	}{
+		{
+			name: "normal",
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"5000"},
+				"X-Ratelimit-Remaining": []string{"4999"},
+				"X-Ratelimit-Reset":     []string{"1700000000"},
+			},
+			exp: &RateLimit{
+				Limit:     5000,
+				Remaining: 4999,
+				Reset:     time.Unix(1700000000, 0),
+			},
+			ok: true,
+		},
+		{
+			name: "no header",
+		},
+		{
+			name: "invalid remaining",
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"5000"},
+				"X-Ratelimit-Remaining": []string{"foo"},
+				"X-Ratelimit-Reset":     []string{"1700000000"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rate, ok := parseRateLimit(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("ok: wanted %v, got %v", tt.ok, ok)
+			}
+			if diff := cmp.Diff(tt.exp, rate); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRateLimit_IsLow(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		rate *RateLimit
+		exp  bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "enough",
+			rate: &RateLimit{Limit: 5000, Remaining: 500, Reset: now.Add(time.Minute)},
+		},
+		{
+			name: "low",
+			rate: &RateLimit{Limit: 5000, Remaining: 499, Reset: now.Add(time.Minute)},
+			exp:  true,
+		},
+		{
+			name: "reset",
+			rate: &RateLimit{Limit: 5000, Remaining: 0, Reset: now},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.rate.IsLow(now); got != tt.exp {
+				t.Fatalf("wanted %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestClient_IsRateLimitLow(t *testing.T) {
+	t.Parallel()
+	tracker := &rateLimitTracker{appID: 1, installationID: 2}
+	client := &Client{rateLimit: tracker}
+	if client.IsRateLimitLow() {
+		t.Fatal("rate limit must not be low before API calls")
+	}
+	tracker.update(&http.Request{}, http.Header{
+		"X-Ratelimit-Limit":     []string{"5000"},
+		"X-Ratelimit-Remaining": []string{"10"},
+		"X-Ratelimit-Reset":     []string{"4000000000"},
+	})
+	if !client.IsRateLimitLow() {
+		t.Fatal("rate limit must be low")
+	}
+}
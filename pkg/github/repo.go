@@ -2,10 +2,16 @@ package github
 
 import (
 	"context"
+	"errors"
+	"net/http"
 )
 
 type RepositoriesService interface {
+	GetCommitSHA1(ctx context.Context, owner, repo, ref, lastSHA string) (string, *Response, error)
+	Get(ctx context.Context, owner, repo string) (*Repository, *Response, error)
 	GetCommit(ctx context.Context, owner, repo, sha string, opts *ListOptions) (*RepositoryCommit, *Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opts *RepositoryContentGetOptions) (*RepositoryContent, []*RepositoryContent, *Response, error)
+	CompareCommits(ctx context.Context, owner, repo string, base, head string, opts *ListOptions) (*CommitsComparison, *Response, error)
 }
 
 func (client *Client) GetCommit(ctx context.Context, owner, repo, sha string) (*RepositoryCommit, *Response, error) {
@@ -19,6 +25,7 @@ func (client *Client) GetCommit(ctx context.Context, owner, repo, sha string) (*
 	if resp.NextPage == 0 {
 		return baseCommit, resp, err
 	}
+	opt.Page = resp.NextPage
 	// https://docs.github.com/en/rest/commits/commits#get-a-commit
 	// Note: If there are more than 300 files in the commit diff,
 	// the response will include pagination link headers for the remaining files, up to a limit of 3000 files
@@ -35,3 +42,65 @@ func (client *Client) GetCommit(ctx context.Context, owner, repo, sha string) (*
 	}
 	return baseCommit, resp, err
 }
+
+// CompareCommitFiles returns files changed between base and head.
+// At most MaxCompareFiles files are returned.
+func (client *Client) CompareCommitFiles(ctx context.Context, owner, repo, base, head string) ([]*CommitFile, *Response, error) {
+	// https://docs.github.com/en/rest/commits/commits#compare-two-commits
+	// When using paging, the list of changed files is only shown on the first page of results,
+	// so only the first page is requested.
+	comparison, resp, err := client.repo.CompareCommits(ctx, owner, repo, base, head, nil)
+	if err != nil {
+		return nil, resp, err
+	}
+	return comparison.Files, resp, nil
+}
+
+// GetFile returns a file.
+// If ref is empty, the repository's default branch is used.
+func (client *Client) GetFile(ctx context.Context, owner, repo, path, ref string) (*RepositoryContent, *Response, error) {
+	var opts *RepositoryContentGetOptions
+	if ref != "" {
+		opts = &RepositoryContentGetOptions{
+			Ref: ref,
+		}
+	}
+	file, _, resp, err := client.repo.GetContents(ctx, owner, repo, path, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+	if file == nil {
+		return nil, resp, errors.New("path isn't a file")
+	}
+	return file, resp, nil
+}
+
+// GetFileContent returns the decoded content of a file.
+// If ref is empty, the repository's default branch is used.
+func (client *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, *Response, error) {
+	file, resp, err := client.GetFile(ctx, owner, repo, path, ref)
+	if err != nil {
+		return "", resp, err
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return "", resp, err
+	}
+	return content, resp, nil
+}
+
+func (client *Client) GetRepo(ctx context.Context, owner, repo string) (*Repository, *Response, error) {
+	return client.repo.Get(ctx, owner, repo)
+}
+
+// RefExists returns true if the branch, tag, or commit exists in the repository.
+func (client *Client) RefExists(ctx context.Context, owner, repo, ref string) (bool, error) {
+	_, resp, err := client.repo.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err == nil {
+		return true, nil
+	}
+	if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"sort"
+)
+
+type GitService interface {
+	GetTree(ctx context.Context, owner string, repo string, sha string, recursive bool) (*Tree, *Response, error)
+}
+
+const (
+	// https://docs.github.com/en/rest/pulls/pulls#list-pull-requests-files
+	MaxPRFiles = 3000
+	// https://docs.github.com/en/rest/commits/commits#get-a-commit
+	MaxCommitFiles = 3000
+	// https://docs.github.com/en/rest/commits/commits#compare-two-commits
+	MaxCompareFiles = 300
+)
+
+// DiffTrees lists files changed between two commits by comparing their git trees recursively.
+// This isn't affected by the limit of the number of files of REST API,
+// but the returned boolean is true if either tree is truncated by the limit of Git Database API.
+// Renames are returned as a removed file and an added file.
+func (client *Client) DiffTrees(ctx context.Context, owner, repo, base, head string) ([]*CommitFile, bool, error) {
+	baseBlobs, baseTruncated, err := client.getBlobs(ctx, owner, repo, base)
+	if err != nil {
+		return nil, false, err
+	}
+	headBlobs, headTruncated, err := client.getBlobs(ctx, owner, repo, head)
+	if err != nil {
+		return nil, false, err
+	}
+	files := []*CommitFile{}
+	for p, sha := range headBlobs {
+		baseSHA, ok := baseBlobs[p]
+		if !ok {
+			files = append(files, newTreeDiffFile(p, "added"))
+			continue
+		}
+		if baseSHA != sha {
+			files = append(files, newTreeDiffFile(p, "modified"))
+		}
+	}
+	for p := range baseBlobs {
+		if _, ok := headBlobs[p]; !ok {
+			files = append(files, newTreeDiffFile(p, "removed"))
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].GetFilename() < files[j].GetFilename()
+	})
+	return files, baseTruncated || headTruncated, nil
+}
+
+func newTreeDiffFile(p, status string) *CommitFile {
+	return &CommitFile{
+		Filename: &p,
+		Status:   &status,
+	}
+}
+
+func (client *Client) getBlobs(ctx context.Context, owner, repo, sha string) (map[string]string, bool, error) {
+	tree, _, err := client.git.GetTree(ctx, owner, repo, sha, true)
+	if err != nil {
+		return nil, false, err
+	}
+	blobs := make(map[string]string, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() != "blob" {
+			continue
+		}
+		blobs[entry.GetPath()] = entry.GetSHA()
+	}
+	return blobs, tree.GetTruncated(), nil
+}
+
+// GetMergeBase returns the SHA of the merge base commit of two commits.
+func (client *Client) GetMergeBase(ctx context.Context, owner, repo, base, head string) (string, *Response, error) {
+	comparison, resp, err := client.repo.CompareCommits(ctx, owner, repo, base, head, &ListOptions{
+		PerPage: 1,
+	})
+	if err != nil {
+		return "", resp, err
+	}
+	return comparison.GetMergeBaseCommit().GetSHA(), resp, nil
+}
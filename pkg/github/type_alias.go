@@ -13,6 +13,7 @@ const (
 
 type (
 	AcceptedError                      = github.AcceptedError
+	CommitsComparison                  = github.CommitsComparison
 	CommitFile                         = github.CommitFile
 	CreateWorkflowDispatchEventRequest = github.CreateWorkflowDispatchEventRequest
 	Deployment                         = github.Deployment
@@ -21,7 +22,10 @@ type (
 	Issue                              = github.Issue
 	IssueComment                       = github.IssueComment
 	IssueCommentEvent                  = github.IssueCommentEvent
+	IssueListCommentsOptions           = github.IssueListCommentsOptions
+	Label                              = github.Label
 	ListOptions                        = github.ListOptions
+	MergeGroup                         = github.MergeGroup
 	PullRequest                        = github.PullRequest
 	PullRequestBranch                  = github.PullRequestBranch
 	PullRequestTargetEvent             = github.PullRequestTargetEvent
@@ -30,8 +34,12 @@ type (
 	ReleaseEvent                       = github.ReleaseEvent
 	Repository                         = github.Repository
 	RepositoryCommit                   = github.RepositoryCommit
+	RepositoryContent                  = github.RepositoryContent
+	RepositoryContentGetOptions        = github.RepositoryContentGetOptions
 	Response                           = github.Response
 	StatusEvent                        = github.StatusEvent
+	Tree                               = github.Tree
+	TreeEntry                          = github.TreeEntry
 	User                               = github.User
 	V3Client                           = github.Client
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/gha-trigger/gha-trigger/pkg/aws"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
@@ -14,19 +15,32 @@ import (
 type GitHubApp struct {
 	Name          string
 	WebhookSecret string
-	Client        *github.Client
+	// Client is the client of the installation configured by installation_id, org, or user
+	Client *github.Client
+	App    *github.App
 }
 
 type AWSClient interface {
 	GetSecretValueWithContext(ctx aws.Context, input *aws.GetSecretValueInput, opts ...aws.Option) (*aws.GetSecretValueOutput, error)
 }
 
-func New(ctx context.Context, awsClient AWSClient, appCfg *config.GitHubApp) (*GitHubApp, error) {
+// New creates a GitHub App. If cache is nil, responses of GitHub API aren't cached.
+func New(ctx context.Context, awsClient AWSClient, appCfg *config.GitHubApp, cache github.Cache) (*GitHubApp, error) {
 	paramNewApp := &github.ParamNewApp{
 		AppID:          appCfg.AppID,
 		InstallationID: appCfg.InstallationID,
 		Org:            appCfg.Org,
 		User:           appCfg.User,
+		BaseURL:        appCfg.BaseURL,
+		UploadURL:      appCfg.UploadURL,
+		Cache:          cache,
+	}
+	for _, p := range appCfg.CABundles {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read a CA bundle: %w", err)
+		}
+		paramNewApp.CABundles = append(paramNewApp.CABundles, b)
 	}
 	input := &aws.GetSecretValueInput{
 		SecretId: util.StrP(appCfg.Secret.SecretID),
@@ -49,13 +63,14 @@ func New(ctx context.Context, awsClient AWSClient, appCfg *config.GitHubApp) (*G
 	if secret.InstallationID != 0 {
 		paramNewApp.InstallationID = secret.InstallationID
 	}
-	gh, err := github.NewApp(ctx, paramNewApp)
+	app, err := github.NewApp(paramNewApp)
 	if err != nil {
 		return nil, fmt.Errorf("create a GitHub Client: %w", err)
 	}
 	return &GitHubApp{
 		Name:          appCfg.Name,
 		WebhookSecret: secret.WebhookSecret,
-		Client:        gh,
+		Client:        app.Client(),
+		App:           app,
 	}, nil
 }
@@ -2,12 +2,95 @@ package lambda
 
 import (
 	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
 
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 	"github.com/gha-trigger/gha-trigger/pkg/util"
 	"go.uber.org/zap"
 )
 
+// Input is a payload of Lambda Function.
+// It is a webhook request from API Gateway, a scheduled event from Amazon EventBridge, or messages from Amazon SQS.
+type Input struct {
+	domain.Request
+	// https://docs.aws.amazon.com/eventbridge/latest/userguide/eb-run-lambda-schedule.html
+	DetailType string    `json:"detail-type"`
+	Time       time.Time `json:"time"`
+	// https://docs.aws.amazon.com/lambda/latest/dg/with-sqs.html
+	Records []events.SQSMessage `json:"Records"`
+}
+
+// Output is a response of Lambda Function.
+// BatchItemFailures is returned for Amazon SQS so that only failed messages are retried.
+// Lambda reads it only if the event source mapping's function_response_types includes ReportBatchItemFailures.
+type Output struct {
+	BatchItemFailures []events.SQSBatchItemFailure `json:"batchItemFailures,omitempty"`
+}
+
+const detailTypeScheduledEvent = "Scheduled Event"
+
+func (handler *Handler) Handle(ctx context.Context, input *Input) (*Output, error) {
+	handler.reload(ctx)
+	defer handler.flushTelemetry(ctx)
+	if len(input.Records) != 0 {
+		return handler.DoQueue(ctx, input.Records), nil
+	}
+	if input.DetailType == detailTypeScheduledEvent {
+		return nil, handler.DoSchedule(ctx, input.Time)
+	}
+	return nil, handler.Do(ctx, &input.Request)
+}
+
+// DoQueue processes webhook requests enqueued to Amazon SQS.
+// Failed messages are retried by SQS and moved to the dead-letter queue by the redrive policy.
+// The event source mapping must set ReportBatchItemFailures in function_response_types.
+// Otherwise Lambda ignores BatchItemFailures and deletes the whole batch, so failed messages are lost.
+func (handler *Handler) DoQueue(ctx context.Context, records []events.SQSMessage) *Output {
+	logger := handler.logger
+	logger.Info("start processing messages")
+	defer logger.Info("end processing messages")
+	output := &Output{}
+	for _, record := range records {
+		logger := logger.With(zap.String("sqs_message_id", record.MessageId))
+		req := &domain.Request{}
+		if err := json.Unmarshal([]byte(record.Body), req); err != nil {
+			// the message is never processed successfully, so it isn't retried
+			logger.Error("parse a message as a request", zap.Error(err))
+			continue
+		}
+		err := handler.getController().Process(ctx, logger, req)
+		if err == nil {
+			continue
+		}
+		if util.IsWarn(err) {
+			logger.Warn("handle a request", zap.Error(err))
+			continue
+		}
+		logger.Error("handle a request", zap.Error(err))
+		output.BatchItemFailures = append(output.BatchItemFailures, events.SQSBatchItemFailure{
+			ItemIdentifier: record.MessageId,
+		})
+	}
+	return output
+}
+
+// DoSchedule runs scheduled workflows.
+// The EventBridge rule must invoke the function every minute, e.g. `rate(1 minute)`.
+func (handler *Handler) DoSchedule(ctx context.Context, t time.Time) error {
+	logger := handler.logger
+	logger.Info("start a scheduled event")
+	defer logger.Info("end a scheduled event")
+	err := handler.getController().RunSchedules(ctx, logger, t)
+	if util.IsWarn(err) {
+		logger.Warn("run scheduled workflows", zap.Error(err))
+		return nil
+	}
+	return err
+}
+
 func (handler *Handler) Do(ctx context.Context, req *domain.Request) error {
 	// func (handler *Handler) Do(ctx context.Context, e interface{}) (*Response, error) {
 	// 	logger := handler.logger
@@ -23,10 +106,21 @@ func (handler *Handler) Do(ctx context.Context, req *domain.Request) error {
 	logger.Info("start a request")
 	defer logger.Info("end a request")
 
-	err := handler.ctrl.Do(ctx, logger, req)
+	err := handler.getController().Do(ctx, logger, req)
 	if util.IsWarn(err) {
 		logger.Warn("handle a request", zap.Error(err))
 		return nil
 	}
 	return err
 }
+
+// flushTelemetry exports metrics and spans at the end of each invocation
+// because Lambda Function is frozen after the invocation and they may never be exported.
+func (handler *Handler) flushTelemetry(ctx context.Context) {
+	if err := handler.tracing.ForceFlush(ctx); err != nil {
+		handler.logger.Warn("export spans", zap.Error(err))
+	}
+	if err := handler.metrics.ForceFlush(ctx); err != nil {
+		handler.logger.Warn("export metrics", zap.Error(err))
+	}
+}
@@ -2,9 +2,14 @@ package lambda
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 )
 
@@ -16,6 +21,14 @@ func (ctrl *mockController) Do(ctx context.Context, logger *zap.Logger, req *dom
 	return ctrl.err
 }
 
+func (ctrl *mockController) Process(ctx context.Context, logger *zap.Logger, req *domain.Request) error {
+	return ctrl.err
+}
+
+func (ctrl *mockController) RunSchedules(ctx context.Context, logger *zap.Logger, t time.Time) error {
+	return ctrl.err
+}
+
 func TestHandler_Do(t *testing.T) {
 	t.Parallel()
 	logger, _ := zap.NewProduction()
@@ -51,3 +64,96 @@ func TestHandler_Do(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_Handle(t *testing.T) {
+	t.Parallel()
+	logger, _ := zap.NewProduction()
+	tests := []struct {
+		name    string
+		handler *Handler
+		wantErr bool
+		input   *Input
+		exp     *Output
+	}{
+		{
+			name: "webhook",
+			handler: &Handler{
+				logger: logger,
+				ctrl:   &mockController{},
+			},
+			input: &Input{},
+		},
+		{
+			name: "schedule",
+			handler: &Handler{
+				logger: logger,
+				ctrl:   &mockController{},
+			},
+			input: &Input{
+				DetailType: "Scheduled Event",
+				Time:       time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "schedule failure isn't retried",
+			handler: &Handler{
+				logger: logger,
+				ctrl: &mockController{
+					err: util.WithWarn(errors.New("foo")),
+				},
+			},
+			input: &Input{
+				DetailType: "Scheduled Event",
+				Time:       time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "sqs",
+			handler: &Handler{
+				logger: logger,
+				ctrl: &mockController{
+					err: errors.New("foo"),
+				},
+			},
+			input: &Input{
+				Records: []events.SQSMessage{
+					{
+						MessageId: "xxx",
+						Body:      `{"body-json": "{}", "params": {"header": {}}}`,
+					},
+					{
+						MessageId: "yyy",
+						Body:      "invalid json",
+					},
+				},
+			},
+			exp: &Output{
+				BatchItemFailures: []events.SQSBatchItemFailure{
+					{
+						ItemIdentifier: "xxx",
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := tt.handler.Handle(ctx, tt.input)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(output, tt.exp); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -4,92 +4,257 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
 	"github.com/gha-trigger/gha-trigger/pkg/aws"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/configsource"
 	"github.com/gha-trigger/gha-trigger/pkg/controller"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 	"github.com/gha-trigger/gha-trigger/pkg/github"
 	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
+	"github.com/gha-trigger/gha-trigger/pkg/queue"
+	"github.com/gha-trigger/gha-trigger/pkg/repoconfig"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
 	"github.com/suzuki-shunsuke/go-osenv/osenv"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
 )
 
 type Handler struct {
-	logger *zap.Logger
-	ctrl   Controller
+	logger  *zap.Logger
+	osEnv   osenv.OSEnv
+	loader  *configsource.Loader
+	metrics *metrics.Provider
+	tracing *tracing.Provider
+
+	mu   sync.RWMutex
+	ctrl Controller
+
+	// apps, apiCache, and repoConfigs are reused across reloads, so installation clients and cached API responses aren't dropped.
+	// They're accessed only by newController, which isn't called concurrently.
+	apps        map[int64]*reusableApp
+	apiCache    *reusableCache
+	repoConfigs *repoconfig.Loader
+}
+
+// reusableApp is a GitHub App with the configuration it was created from.
+// It's reused while the configuration isn't changed, so the secret isn't read again.
+// To rotate the secret, change version_id or restart the function.
+type reusableApp struct {
+	cfg *config.GitHubApp
+	app *githubapp.GitHubApp
+}
+
+type reusableCache struct {
+	cfg   *config.GitHubAPICache
+	cache github.Cache
 }
 
 type Controller interface {
 	Do(ctx context.Context, logger *zap.Logger, req *domain.Request) error
+	Process(ctx context.Context, logger *zap.Logger, req *domain.Request) error
+	RunSchedules(ctx context.Context, logger *zap.Logger, t time.Time) error
 }
 
 func New(ctx context.Context, logger *zap.Logger) (*Handler, error) {
-	// read config
-	cfg := &config.Config{}
 	osEnv := osenv.New()
-	if err := readConfig(cfg, osEnv); err != nil {
+	interval, err := getRefreshInterval(osEnv)
+	if err != nil {
+		return nil, err
+	}
+	// The region of the configuration source is given by the environment variable AWS_REGION
+	src, err := configsource.New(osEnv, aws.New(nil), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get the configuration source: %w", err)
+	}
+	mp, err := metrics.Setup(ctx, osEnv)
+	if err != nil {
+		return nil, fmt.Errorf("set up metrics: %w", err)
+	}
+	tp, err := tracing.Setup(ctx, osEnv)
+	if err != nil {
+		return nil, fmt.Errorf("set up tracing: %w", err)
+	}
+	loader := configsource.NewLoader(src, interval)
+	b, err := loader.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read the configuration: %w", err)
+	}
+	handler := &Handler{
+		logger:      logger,
+		osEnv:       osEnv,
+		loader:      loader,
+		metrics:     mp,
+		tracing:     tp,
+		repoConfigs: repoconfig.New(),
+	}
+	ctrl, err := handler.newController(ctx, b)
+	if err != nil {
 		return nil, err
 	}
+	handler.ctrl = ctrl
+	return handler, nil
+}
+
+// getRefreshInterval returns the interval to check if the configuration is modified.
+// If CONFIG_REFRESH_INTERVAL isn't set, the configuration isn't reloaded.
+func getRefreshInterval(osEnv osenv.OSEnv) (time.Duration, error) {
+	s := osEnv.Getenv("CONFIG_REFRESH_INTERVAL")
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse CONFIG_REFRESH_INTERVAL as duration: %w", err)
+	}
+	return d, nil
+}
+
+// newController builds a controller from the configuration.
+// The configuration is validated and initialized from scratch, so a controller is never built from a partially initialized configuration.
+// GitHub Apps and the cache of GitHub API are reused if their configurations aren't changed.
+// They're replaced only after the controller is built, so the current controller keeps working if the configuration is invalid.
+func (handler *Handler) newController(ctx context.Context, b []byte) (Controller, error) {
+	cfg := &config.Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parse the configuration as YAML: %w", err)
+	}
 	if err := config.Validate(cfg); err != nil {
 		return nil, fmt.Errorf("configuration is invalid: %w", err)
 	}
-	if err := config.Init(cfg); err != nil {
-		return nil, fmt.Errorf("initialize configuration: %w", err)
+	if err := config.Prepare(cfg); err != nil {
+		return nil, err //nolint:wrapcheck
 	}
 	// read secret
 	awsClient := aws.New(cfg.AWS)
 	numGitHubApps := len(cfg.GitHubApps)
 	ghApps := make(map[int64]*githubapp.GitHubApp, numGitHubApps)
 	ghs := make(map[string]*github.Client, numGitHubApps)
+	apiCache := handler.getGitHubAPICache(cfg.GitHubAPICache)
+	apps := make(map[int64]*reusableApp, numGitHubApps)
 	for i := 0; i < numGitHubApps; i++ {
 		appCfg := cfg.GitHubApps[i]
-		ghApp, err := githubapp.New(ctx, awsClient, appCfg)
+		app, err := handler.getGitHubApp(ctx, awsClient, appCfg, apiCache)
 		if err != nil {
 			return nil, err
 		}
-		ghApps[appCfg.AppID] = ghApp
-		ghs[appCfg.Name] = ghApp.Client
+		apps[appCfg.AppID] = app
+		ghApps[appCfg.AppID] = app.app
+		ghs[appCfg.Name] = app.app.Client
 	}
 
 	if err := bindGitHubAppToWorkflow(cfg.Repos, ghs); err != nil {
 		return nil, err
 	}
 
-	// initialize handler
-	return &Handler{
-		logger: logger,
-		ctrl:   controller.New(cfg, logger, osEnv, ghApps),
+	if err := config.Init(cfg); err != nil {
+		return nil, fmt.Errorf("initialize configuration: %w", err)
+	}
+
+	q, err := newQueue(cfg.Queue, awsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := audit.New(cfg.Audit, awsClient)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	handler.apps = apps
+	handler.apiCache = apiCache
+	return controller.New(cfg, handler.logger, handler.osEnv, ghApps, q, sink, handler.repoConfigs), nil
+}
+
+// getGitHubApp returns the current GitHub App if neither its configuration nor the cache is changed.
+func (handler *Handler) getGitHubApp(ctx context.Context, awsClient *aws.Client, appCfg *config.GitHubApp, apiCache *reusableCache) (*reusableApp, error) {
+	if app, ok := handler.apps[appCfg.AppID]; ok && handler.apiCache == apiCache && reflect.DeepEqual(app.cfg, appCfg) {
+		return app, nil
+	}
+	ghApp, err := githubapp.New(ctx, awsClient, appCfg, apiCache.cache)
+	if err != nil {
+		return nil, err
+	}
+	return &reusableApp{
+		cfg: appCfg,
+		app: ghApp,
 	}, nil
 }
 
-func readConfig(cfg *config.Config, osEnv osenv.OSEnv) error {
-	cfgS := osEnv.Getenv("CONFIG")
-	if cfgS == "" {
-		return errors.New("environment variable 'CONFIG' is required")
+// getGitHubAPICache returns the current cache if its configuration isn't changed.
+func (handler *Handler) getGitHubAPICache(cfg *config.GitHubAPICache) *reusableCache {
+	if c := handler.apiCache; c != nil && reflect.DeepEqual(c.cfg, cfg) {
+		return c
 	}
-	if err := yaml.Unmarshal([]byte(cfgS), cfg); err != nil {
-		return fmt.Errorf("parse the configuration as YAML: %w", err)
+	return &reusableCache{
+		cfg:   cfg,
+		cache: newGitHubAPICache(cfg),
 	}
-	return nil
 }
 
-func bindGitHubAppToWorkflow(repos []*config.Repo, ghs map[string]*github.Client) error {
-	numRepos := len(repos)
-	for i := 0; i < numRepos; i++ {
-		repo := repos[i]
-		numEvents := len(repo.Events)
-		gh, ok := ghs[repo.WorkflowGitHubAppName]
-		if !ok {
-			return errors.New("invalid github app name")
+// reload rebuilds the controller if the configuration is modified.
+// If the new configuration is invalid, the current controller keeps being used.
+func (handler *Handler) reload(ctx context.Context) {
+	if handler.loader == nil {
+		return
+	}
+	logger := handler.logger
+	content, err := handler.loader.Refresh(ctx)
+	if err != nil {
+		if !errors.Is(err, configsource.ErrNotModified) {
+			logger.Error("refresh the configuration", zap.Error(err))
 		}
-		repo.GitHub = gh
-		for j := 0; j < numEvents; j++ {
-			ev := repo.Events[j]
-			wfCfg := ev.Workflow
-			wfCfg.GitHub = gh
+		return
+	}
+	ctrl, err := handler.newController(ctx, content.Body)
+	if err != nil {
+		logger.Error("reload the configuration, so the current configuration is used", zap.Error(err))
+		return
+	}
+	handler.mu.Lock()
+	handler.ctrl = ctrl
+	handler.mu.Unlock()
+	handler.loader.Commit(content.ETag)
+	logger.Info("the configuration is reloaded")
+}
+
+func (handler *Handler) getController() Controller {
+	handler.mu.RLock()
+	defer handler.mu.RUnlock()
+	return handler.ctrl
+}
+
+func bindGitHubAppToWorkflow(repos []*config.Repo, ghs map[string]*github.Client) error {
+	for _, repo := range repos {
+		if err := config.BindGitHubApps(repo, ghs); err != nil {
+			return err //nolint:wrapcheck
 		}
 	}
 	return nil
 }
+
+// newGitHubAPICache returns the cache shared by GitHub Apps.
+// The in-memory cache lives while the Lambda Function's execution environment is reused.
+func newGitHubAPICache(cfg *config.GitHubAPICache) github.Cache {
+	if cfg == nil {
+		return nil
+	}
+	return github.NewMemoryCache(cfg.GetMaxBytes())
+}
+
+func newQueue(cfg *config.Queue, awsClient *aws.Client) (queue.Queue, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	switch cfg.Type {
+	case config.QueueTypeSQS:
+		return queue.NewSQS(awsClient, cfg.QueueURL), nil
+	default:
+		return nil, fmt.Errorf("queue type %s isn't supported", cfg.Type)
+	}
+}
@@ -0,0 +1,54 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/githubapp"
+)
+
+func TestHandler_getGitHubAPICache(t *testing.T) {
+	t.Parallel()
+	handler := &Handler{}
+	cache := handler.getGitHubAPICache(&config.GitHubAPICache{Type: "memory"})
+	handler.apiCache = cache
+	if c := handler.getGitHubAPICache(&config.GitHubAPICache{Type: "memory"}); c != cache {
+		t.Fatal("the cache must be reused if the configuration isn't changed")
+	}
+	if c := handler.getGitHubAPICache(&config.GitHubAPICache{Type: "memory", MaxBytes: 100}); c == cache {
+		t.Fatal("the cache must be recreated if the configuration is changed")
+	}
+}
+
+func TestHandler_getGitHubApp(t *testing.T) {
+	t.Parallel()
+	cache := &reusableCache{}
+	newAppCfg := func() *config.GitHubApp {
+		return &config.GitHubApp{
+			Name:  "ci",
+			AppID: 1,
+			Org:   "gha-trigger",
+			Secret: &config.GitHubAppSecretConfig{
+				Type:     "aws_secretsmanager",
+				SecretID: "gha-trigger",
+			},
+		}
+	}
+	app := &reusableApp{
+		cfg: newAppCfg(),
+		app: &githubapp.GitHubApp{Name: "ci"},
+	}
+	handler := &Handler{
+		apps:     map[int64]*reusableApp{1: app},
+		apiCache: cache,
+	}
+	// the secret isn't read, so the AWS client isn't needed
+	got, err := handler.getGitHubApp(context.Background(), nil, newAppCfg(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != app {
+		t.Fatal("the GitHub App must be reused if the configuration isn't changed")
+	}
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/asyncint64"
+	"go.opentelemetry.io/otel/metric/instrument/syncfloat64"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+const instrumentationName = "github.com/gha-trigger/gha-trigger"
+
+type instruments struct {
+	webhooks              syncint64.Counter
+	signatureFailures     syncint64.Counter
+	routeMatches          syncint64.Counter
+	dispatches            syncint64.Counter
+	githubAPIDuration     syncfloat64.Histogram
+	rateLimitRemaining    asyncint64.Gauge
+	rateLimitLow          syncint64.Counter
+	mergeablePollDuration syncfloat64.Histogram
+}
+
+var (
+	inst     *instruments //nolint:gochecknoglobals
+	instOnce sync.Once    //nolint:gochecknoglobals
+	// rateLimits is a map of rateLimitKey and the remaining rate limit
+	rateLimits sync.Map //nolint:gochecknoglobals
+)
+
+// get returns instruments created by the global MeterProvider.
+// The global MeterProvider delegates instruments to the provider set by Setup, even if they are created before Setup.
+func get() *instruments {
+	instOnce.Do(func() {
+		i, err := newInstruments(global.Meter(instrumentationName))
+		if err != nil {
+			otelHandleError(err)
+			i, _ = newInstruments(metric.NewNoopMeter())
+		}
+		inst = i
+	})
+	return inst
+}
+
+func newInstruments(meter metric.Meter) (*instruments, error) { //nolint:cyclop
+	i := &instruments{}
+	var err error
+	if i.webhooks, err = meter.SyncInt64().Counter("gha_trigger.webhooks",
+		instrument.WithDescription("The number of received webhooks")); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.signatureFailures, err = meter.SyncInt64().Counter("gha_trigger.signature_failures",
+		instrument.WithDescription("The number of webhooks whose signature is invalid")); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.routeMatches, err = meter.SyncInt64().Counter("gha_trigger.route_matches",
+		instrument.WithDescription("The number of events matched with each workflow")); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.dispatches, err = meter.SyncInt64().Counter("gha_trigger.dispatches",
+		instrument.WithDescription("The number of workflow dispatches")); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.githubAPIDuration, err = meter.SyncFloat64().Histogram("gha_trigger.github_api.duration",
+		instrument.WithDescription("The latency of GitHub API"),
+		instrument.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.mergeablePollDuration, err = meter.SyncFloat64().Histogram("gha_trigger.mergeable_poll.duration",
+		instrument.WithDescription("The time to wait until pull request's mergeable is computed"),
+		instrument.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.rateLimitRemaining, err = meter.AsyncInt64().Gauge("gha_trigger.github_api.rate_limit_remaining",
+		instrument.WithDescription("The remaining rate limit of GitHub API")); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if i.rateLimitLow, err = meter.SyncInt64().Counter("gha_trigger.github_api.rate_limit_low",
+		instrument.WithDescription("The number of times the remaining rate limit of GitHub API falls below the threshold")); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if err := meter.RegisterCallback([]instrument.Asynchronous{i.rateLimitRemaining}, func(ctx context.Context) {
+		rateLimits.Range(func(key, value interface{}) bool {
+			k := key.(rateLimitKey)    //nolint:forcetypeassert
+			remaining := value.(int64) //nolint:forcetypeassert
+			i.rateLimitRemaining.Observe(ctx, remaining,
+				attribute.Int64("github_app_id", k.appID), attribute.Int64("github_installation_id", k.installationID))
+			return true
+		})
+	}); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return i, nil
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func result(err error) attribute.KeyValue {
+	if err != nil {
+		return attribute.String("result", "failure")
+	}
+	return attribute.String("result", "success")
+}
+
+// RecordWebhook counts a received webhook.
+func RecordWebhook(ctx context.Context, eventType, repo string) {
+	get().webhooks.Add(ctx, 1, attribute.String("event_type", eventType), attribute.String("repo", repo))
+}
+
+// RecordSignatureFailure counts a webhook whose signature is invalid.
+func RecordSignatureFailure(ctx context.Context, appID int64) {
+	get().signatureFailures.Add(ctx, 1, attribute.Int64("github_app_id", appID))
+}
+
+// RecordRouteMatch counts an event matched with a workflow.
+func RecordRouteMatch(ctx context.Context, repo, workflow string) {
+	get().routeMatches.Add(ctx, 1, attribute.String("repo", repo), attribute.String("workflow", workflow))
+}
+
+// RecordDispatch counts a workflow dispatch. repo is the repository where the workflow is run.
+func RecordDispatch(ctx context.Context, repo, workflow string, err error) {
+	get().dispatches.Add(ctx, 1, attribute.String("repo", repo), attribute.String("workflow", workflow), result(err))
+}
+
+// RecordGitHubAPI records the latency of a GitHub API call.
+func RecordGitHubAPI(ctx context.Context, method string, statusCode int, d time.Duration) {
+	get().githubAPIDuration.Record(ctx, durationMS(d), attribute.String("method", method), attribute.Int("status_code", statusCode))
+}
+
+type rateLimitKey struct {
+	appID          int64
+	installationID int64
+}
+
+// SetRateLimitRemaining records the latest remaining rate limit of the GitHub App's installation.
+func SetRateLimitRemaining(appID, installationID, remaining int64) {
+	get()
+	rateLimits.Store(rateLimitKey{appID: appID, installationID: installationID}, remaining)
+}
+
+// RecordRateLimitLow counts that the remaining rate limit of the installation falls below the threshold.
+func RecordRateLimitLow(ctx context.Context, appID, installationID int64) {
+	get().rateLimitLow.Add(ctx, 1, attribute.Int64("github_app_id", appID), attribute.Int64("github_installation_id", installationID))
+}
+
+// RecordMergeablePoll records the time to wait until pull request's mergeable is computed.
+func RecordMergeablePoll(ctx context.Context, d time.Duration, err error) {
+	get().mergeablePollDuration.Record(ctx, durationMS(d), result(err))
+}
@@ -0,0 +1,69 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/metric/global"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestRecord(t *testing.T) { //nolint:paralleltest
+	reader := sdkmetric.NewManualReader()
+	global.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	}))
+	t.Cleanup(srv.Close)
+	client := &http.Client{Transport: metrics.NewTransport(http.DefaultTransport, 1)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	metrics.RecordWebhook(ctx, "push", "gha-trigger/example")
+	metrics.RecordSignatureFailure(ctx, 1)
+	metrics.RecordRouteMatch(ctx, "gha-trigger/example", "test.yaml")
+	metrics.RecordDispatch(ctx, "gha-trigger/example-ci", "test.yaml", errors.New("not found"))
+	metrics.RecordMergeablePoll(ctx, time.Second, nil)
+	metrics.SetRateLimitRemaining(1, 10, 4999)
+	metrics.RecordRateLimitLow(ctx, 1, 10)
+
+	rm, err := reader.Collect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	exp := []string{
+		"gha_trigger.dispatches",
+		"gha_trigger.github_api.duration",
+		"gha_trigger.github_api.rate_limit_low",
+		"gha_trigger.github_api.rate_limit_remaining",
+		"gha_trigger.mergeable_poll.duration",
+		"gha_trigger.route_matches",
+		"gha_trigger.signature_failures",
+		"gha_trigger.webhooks",
+	}
+	if diff := cmp.Diff(exp, names); diff != "" {
+		t.Fatal(diff)
+	}
+}
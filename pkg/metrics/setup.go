@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/suzuki-shunsuke/go-osenv/osenv"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/metric/global"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Provider flushes and shuts down metrics. A nil Provider does nothing.
+type Provider struct {
+	provider *sdkmetric.MeterProvider
+}
+
+// Setup configures the metrics exporter by the environment variable METRICS_EXPORTER.
+//
+// - stdout: metrics are written to the standard output
+// - otlp: metrics are sent by OTLP over HTTP. The endpoint is configured by OTEL_EXPORTER_OTLP_ENDPOINT
+//
+// If METRICS_EXPORTER isn't set, metrics aren't recorded.
+func Setup(ctx context.Context, osEnv osenv.OSEnv) (*Provider, error) {
+	var reader sdkmetric.Reader
+	switch exporter := osEnv.Getenv("METRICS_EXPORTER"); exporter {
+	case "":
+		return nil, nil
+	case ExporterStdout:
+		exp, err := stdoutmetric.New()
+		if err != nil {
+			return nil, fmt.Errorf("create a stdout exporter: %w", err)
+		}
+		reader = sdkmetric.NewPeriodicReader(exp)
+	case ExporterOTLP:
+		exp, err := otlpmetrichttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create an OTLP exporter: %w", err)
+		}
+		reader = sdkmetric.NewPeriodicReader(exp)
+	default:
+		return nil, fmt.Errorf("METRICS_EXPORTER is invalid: %s", exporter)
+	}
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	global.SetMeterProvider(provider)
+	return &Provider{
+		provider: provider,
+	}, nil
+}
+
+// ForceFlush exports recorded metrics.
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	return p.provider.ForceFlush(ctx) //nolint:wrapcheck
+}
+
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	return p.provider.Shutdown(ctx) //nolint:wrapcheck
+}
+
+func otelHandleError(err error) {
+	otel.Handle(err)
+}
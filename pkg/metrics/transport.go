@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Transport records the latency of GitHub API.
+type Transport struct {
+	base  http.RoundTripper
+	appID int64
+}
+
+func NewTransport(base http.RoundTripper, appID int64) *Transport {
+	return &Transport{
+		base:  base,
+		appID: appID,
+	}
+}
+
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := tr.base.RoundTrip(req)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	RecordGitHubAPI(req.Context(), req.Method, statusCode, time.Since(start))
+	return resp, err //nolint:wrapcheck
+}
@@ -0,0 +1,12 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+)
+
+// Queue passes validated webhook requests from the receiver to the worker.
+type Queue interface {
+	Enqueue(ctx context.Context, req *domain.Request) error
+}
@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gha-trigger/gha-trigger/pkg/aws"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type SQSClient interface {
+	SendMessageWithContext(ctx aws.Context, input *aws.SendMessageInput, opts ...aws.Option) (*aws.SendMessageOutput, error)
+}
+
+type SQS struct {
+	client   SQSClient
+	queueURL string
+}
+
+func NewSQS(client SQSClient, queueURL string) *SQS {
+	return &SQS{
+		client:   client,
+		queueURL: queueURL,
+	}
+}
+
+func (q *SQS) Enqueue(ctx context.Context, req *domain.Request) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal a request as JSON: %w", err)
+	}
+	if _, err := q.client.SendMessageWithContext(ctx, &aws.SendMessageInput{
+		QueueUrl:    util.StrP(q.queueURL),
+		MessageBody: util.StrP(string(b)),
+	}); err != nil {
+		return fmt.Errorf("send a message to Amazon SQS: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repoconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+// GitHub reads the configuration file from the source repository.
+type GitHub interface {
+	GetFile(ctx context.Context, owner, repo, path, ref string) (*github.RepositoryContent, *github.Response, error)
+}
+
+// Loader reads the configuration file in the source repository's default branch.
+// The file is read at most once per RepoConfig.CacheTTL per repository, so GitHub API isn't called at every event.
+// Merged configurations are cached by the file's blob SHA, so the file is parsed and initialized only when it's changed.
+// The Loader is shared across reloads of the central configuration, and the cached file is merged with the new central configuration.
+type Loader struct {
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	// file is nil if the file doesn't exist
+	file *github.RepositoryContent
+	// central is the central configuration which repo is merged from
+	central *config.Repo
+	repo    *config.Repo
+	// err is the error of the invalid file, which is returned until the file is changed
+	err error
+	// expiration is the time when the file is read again
+	expiration time.Time
+}
+
+func New() *Loader {
+	return &Loader{
+		cache: map[string]*cacheEntry{},
+	}
+}
+
+// Get returns the central configuration merged with the file in the source repository.
+// If the file doesn't exist, central is returned as is.
+// ghs is a map of GitHub App names and clients, which are bound to workflows.
+func (l *Loader) Get(ctx context.Context, logger *zap.Logger, gh GitHub, central *config.Repo, ghs map[string]*github.Client) (*config.Repo, error) {
+	key := central.RepoOwner + "/" + central.RepoName
+	now := time.Now()
+	l.mu.Lock()
+	entry, ok := l.cache[key]
+	l.mu.Unlock()
+
+	var newEntry *cacheEntry
+	switch {
+	case ok && now.Before(entry.expiration) && entry.central == central:
+		return entry.repo, entry.err
+	case ok && now.Before(entry.expiration):
+		// the central configuration is reloaded, so the file read before is merged again
+		newEntry = newCacheEntry(ctx, logger, entry.file, central, ghs, entry.expiration)
+	default:
+		// the lock isn't held while GitHub API is called, so the file may be read concurrently.
+		file, resp, err := gh.GetFile(ctx, central.RepoOwner, central.RepoName, central.RepoConfig.GetPath(), "")
+		if err != nil {
+			if resp == nil || resp.StatusCode != http.StatusNotFound {
+				return nil, fmt.Errorf("get the configuration file from the source repository: %w", err)
+			}
+			file = nil
+		}
+		expiration := now.Add(central.RepoConfig.GetCacheTTL())
+		if ok && entry.central == central && entry.file != nil && file != nil && entry.file.GetSHA() == file.GetSHA() {
+			newEntry = &cacheEntry{
+				file:       file,
+				central:    central,
+				repo:       entry.repo,
+				err:        entry.err,
+				expiration: expiration,
+			}
+		} else {
+			newEntry = newCacheEntry(ctx, logger, file, central, ghs, expiration)
+		}
+	}
+	l.mu.Lock()
+	l.cache[key] = newEntry
+	l.mu.Unlock()
+	return newEntry.repo, newEntry.err
+}
+
+func newCacheEntry(ctx context.Context, logger *zap.Logger, file *github.RepositoryContent, central *config.Repo, ghs map[string]*github.Client, expiration time.Time) *cacheEntry {
+	entry := &cacheEntry{
+		file:       file,
+		central:    central,
+		repo:       central,
+		expiration: expiration,
+	}
+	if file == nil {
+		return entry
+	}
+	repo, err := load(ctx, logger, file, central, ghs)
+	if err != nil {
+		// The file is fixed by the repository owner, so the error is logged at error level to be noticed,
+		// but the event isn't retried.
+		path := central.RepoConfig.GetPath()
+		entry.repo = nil
+		entry.err = util.WithWarn(fmt.Errorf("the configuration file %s in the source repository is invalid: %w", path, err))
+		logger.Error("the configuration file in the source repository is invalid, so no workflow is run until it's fixed",
+			zap.String("repo_config_path", path), zap.Error(err))
+		return entry
+	}
+	entry.repo = repo
+	return entry
+}
+
+func load(ctx context.Context, logger *zap.Logger, file *github.RepositoryContent, central *config.Repo, ghs map[string]*github.Client) (*config.Repo, error) {
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decode the file content: %w", err)
+	}
+	repoFile := &config.RepoFile{}
+	if err := yaml.Unmarshal([]byte(content), repoFile); err != nil {
+		return nil, fmt.Errorf("parse the file as YAML: %w", err)
+	}
+	repo, err := config.MergeRepoFile(central, repoFile)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if err := config.BindGitHubApps(repo, ghs); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	// the reachability is validated as the central configuration, so the file isn't rejected by a temporary failure of GitHub
+	if err := config.ValidateWorkflowTargets(ctx, repo, ghs); err != nil {
+		logger.Warn("validate repositories where workflows are run", zap.Error(err))
+	}
+	for _, ev := range repo.Events {
+		if !ev.OnFromWorkflow {
+			continue
+		}
+		wf := ev.Workflow
+		if err := config.ReadWorkflowOn(ctx, ghs[wf.GetGitHubAppName(repo)], wf.GetRepoOwner(repo), wf.GetRepoName(repo), ev); err != nil {
+			return nil, fmt.Errorf("read on from the workflow file: %w", err)
+		}
+	}
+	if err := config.InitEvents(repo); err != nil {
+		return nil, fmt.Errorf("initialize events: %w", err)
+	}
+	return repo, nil
+}
@@ -0,0 +1,193 @@
+package repoconfig_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/repoconfig"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"go.uber.org/zap"
+)
+
+type fakeGitHub struct {
+	file  *github.RepositoryContent
+	resp  *github.Response
+	err   error
+	calls int
+}
+
+func (gh *fakeGitHub) GetFile(ctx context.Context, owner, repo, path, ref string) (*github.RepositoryContent, *github.Response, error) {
+	gh.calls++
+	return gh.file, gh.resp, gh.err
+}
+
+func newFile(sha, content string) *github.RepositoryContent {
+	return &github.RepositoryContent{
+		SHA:      util.StrP(sha),
+		Encoding: util.StrP("base64"),
+		Content:  util.StrP(base64.StdEncoding.EncodeToString([]byte(content))),
+	}
+}
+
+func TestLoader_Get(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger, _ := zap.NewProduction()
+	central := &config.Repo{
+		RepoOwner:             "suzuki-shunsuke",
+		RepoName:              "foo",
+		WorkflowGitHubAppName: "ci",
+		CIRepoName:            "foo-ci",
+		// the file is read at every call
+		RepoConfig: &config.RepoConfig{
+			CacheTTL: time.Nanosecond,
+		},
+	}
+	// GitHub API to confirm the GitHub App can access the CI repository
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "foo-ci"}`)) //nolint:errcheck
+	}))
+	defer srv.Close()
+	v3 := github.NewV3Client(srv.Client())
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3.BaseURL = baseURL
+	ghs := map[string]*github.Client{
+		"ci": github.New(v3),
+	}
+	loader := repoconfig.New()
+
+	gh := &fakeGitHub{
+		file: newFile("1", `
+events:
+  - matches:
+      - events: [{name: pull_request}]
+    workflow:
+      workflow_file_name: test.yaml
+`),
+	}
+	repo, err := loader.Get(ctx, logger, gh, central, ghs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.Events) != 1 || repo.Events[0].Workflow.WorkflowFileName != "test.yaml" {
+		t.Fatal("events must be read from the file")
+	}
+	if types := repo.Events[0].Matches[0].Events[0].Types; len(types) != 3 { //nolint:gomnd
+		t.Fatalf("events must be initialized: %v", types)
+	}
+	if len(central.Events) != 0 {
+		t.Fatal("the central configuration must not be changed")
+	}
+
+	// cached by SHA
+	cached, err := loader.Get(ctx, logger, gh, central, ghs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != repo {
+		t.Fatal("the cached configuration must be returned")
+	}
+
+	// invalid file
+	gh.file = newFile("2", "ci_repo_name: bar-ci")
+	if _, err := loader.Get(ctx, logger, gh, central, ghs); !util.IsWarn(err) {
+		t.Fatalf("a warning error must be returned: %v", err)
+	}
+
+	// file not found
+	gh.file = nil
+	gh.resp = &github.Response{
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+		},
+	}
+	gh.err = errors.New("not found")
+	repo, err = loader.Get(ctx, logger, gh, central, ghs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo != central {
+		t.Fatal("the central configuration must be returned")
+	}
+}
+
+func TestLoader_Get_cacheTTL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger, _ := zap.NewProduction()
+	central := &config.Repo{
+		RepoOwner:             "suzuki-shunsuke",
+		RepoName:              "foo",
+		WorkflowGitHubAppName: "ci",
+		CIRepoName:            "foo-ci",
+		RepoConfig: &config.RepoConfig{
+			CacheTTL: time.Hour,
+		},
+	}
+	loader := repoconfig.New()
+	gh := &fakeGitHub{
+		file: newFile("1", "ci_repo_name: bar-ci"),
+	}
+	for i := 0; i < 2; i++ {
+		// the invalid file is reported until it's read again
+		if _, err := loader.Get(ctx, logger, gh, central, map[string]*github.Client{}); !util.IsWarn(err) {
+			t.Fatalf("a warning error must be returned: %v", err)
+		}
+	}
+	if gh.calls != 1 {
+		t.Fatalf("the file must be read once in the TTL: %d", gh.calls)
+	}
+}
+
+func TestLoader_Get_reload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger, _ := zap.NewProduction()
+	newCentral := func(ciRepoName string) *config.Repo {
+		return &config.Repo{
+			RepoOwner:             "suzuki-shunsuke",
+			RepoName:              "foo",
+			WorkflowGitHubAppName: "ci",
+			CIRepoName:            ciRepoName,
+			RepoConfig: &config.RepoConfig{
+				CacheTTL: time.Hour,
+			},
+		}
+	}
+	loader := repoconfig.New()
+	gh := &fakeGitHub{
+		err: errors.New("not found"),
+		resp: &github.Response{
+			Response: &http.Response{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+	}
+	central := newCentral("foo-ci")
+	if _, err := loader.Get(ctx, logger, gh, central, map[string]*github.Client{}); err != nil {
+		t.Fatal(err)
+	}
+	// the central configuration is reloaded
+	reloaded := newCentral("bar-ci")
+	repo, err := loader.Get(ctx, logger, gh, reloaded, map[string]*github.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo != reloaded {
+		t.Fatal("the reloaded central configuration must be returned")
+	}
+	if gh.calls != 1 {
+		t.Fatalf("the file must not be read again at reload: %d", gh.calls)
+	}
+}
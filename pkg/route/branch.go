@@ -8,37 +8,38 @@ import (
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 )
 
+// getBranch returns the branch compared with branch filters.
+// pull_request and merge_group events are compared by the base branch.
+func getBranch(event *domain.Event) (string, bool) {
+	if pr := event.Payload.PullRequest; pr != nil {
+		return pr.GetBase().GetRef(), true
+	}
+	if mg := event.Payload.MergeGroup; mg != nil {
+		return strings.TrimPrefix(mg.GetBaseRef(), "refs/heads/"), true
+	}
+	if event.Payload.Ref != "" {
+		return strings.TrimPrefix(event.Payload.Ref, "refs/heads/"), true
+	}
+	return "", false
+}
+
 func matchBranches(ctx context.Context, matchConfig *config.Match, event *domain.Event) (bool, error) {
 	if len(matchConfig.Branches) == 0 {
 		return true, nil
 	}
-	if event.Payload.PullRequest != nil {
-		base := event.Payload.PullRequest.GetBase()
-		ref := base.GetRef()
-		for _, branch := range matchConfig.Branches {
-			f, err := branch.Match(ref)
-			if err != nil {
-				return false, err
-			}
-			// OR condition
-			if f {
-				return true, nil
-			}
-		}
+	ref, ok := getBranch(event)
+	if !ok {
 		return false, nil
 	}
-	if event.Payload.Ref != "" {
-		ref := strings.TrimPrefix(event.Payload.Ref, "refs/heads/")
-		for _, branch := range matchConfig.Branches {
-			f, err := branch.Match(ref)
-			if err != nil {
-				return false, err
-			}
-			if f {
-				return true, nil
-			}
+	for _, branch := range matchConfig.Branches {
+		f, err := branch.Match(ref)
+		if err != nil {
+			return false, err
+		}
+		// OR condition
+		if f {
+			return true, nil
 		}
-		return false, nil
 	}
 	return false, nil
 }
@@ -47,32 +48,18 @@ func matchBranchesIgnore(ctx context.Context, matchConfig *config.Match, event *
 	if len(matchConfig.BranchesIgnore) == 0 {
 		return true, nil
 	}
-	if event.Payload.PullRequest != nil {
-		base := event.Payload.PullRequest.GetBase()
-		ref := base.GetRef()
-		for _, branch := range matchConfig.BranchesIgnore {
-			f, err := branch.Match(ref)
-			if err != nil {
-				return false, err
-			}
-			if f {
-				return false, nil
-			}
-		}
+	ref, ok := getBranch(event)
+	if !ok {
 		return true, nil
 	}
-	if event.Payload.Ref != "" {
-		ref := strings.TrimPrefix(event.Payload.Ref, "refs/heads/")
-		for _, branch := range matchConfig.BranchesIgnore {
-			f, err := branch.Match(ref)
-			if err != nil {
-				return false, err
-			}
-			if f {
-				return false, nil
-			}
+	for _, branch := range matchConfig.BranchesIgnore {
+		f, err := branch.Match(ref)
+		if err != nil {
+			return false, err
+		}
+		if f {
+			return false, nil
 		}
-		return true, nil
 	}
 	return true, nil
 }
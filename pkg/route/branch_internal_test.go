@@ -71,6 +71,25 @@ func Test_matchBranches(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "merge_group match",
+			exp:  true,
+			matchConfig: &config.Match{
+				Branches: []*config.StringMatch{
+					{
+						Type:  "equal",
+						Value: "main",
+					},
+				},
+			},
+			event: &domain.Event{
+				Payload: &domain.Payload{
+					MergeGroup: &github.MergeGroup{
+						BaseRef: util.StrP("refs/heads/main"),
+					},
+				},
+			},
+		},
 		{
 			name: "ref not match",
 			matchConfig: &config.Match{
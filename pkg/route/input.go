@@ -0,0 +1,42 @@
+package route
+
+import (
+	"strings"
+
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+)
+
+func eventTypeInput(event *domain.Event) interface{} {
+	if event.Payload.Action == "" {
+		return event.Type
+	}
+	return event.Type + ":" + event.Payload.Action
+}
+
+func branchInput(event *domain.Event) interface{} {
+	branch, ok := getBranch(event)
+	if !ok {
+		return nil
+	}
+	return branch
+}
+
+func tagInput(event *domain.Event) interface{} {
+	tag := strings.TrimPrefix(event.Payload.Ref, "refs/tags/")
+	if tag == event.Payload.Ref {
+		// the ref isn't a tag
+		return nil
+	}
+	return tag
+}
+
+// pathInput returns changed files only if they have already been got.
+func pathInput(event *domain.Event) interface{} {
+	if event.ChangedFilesUnknown {
+		return "changed files are unknown"
+	}
+	if event.ChangedFiles == nil {
+		return nil
+	}
+	return event.ChangedFiles
+}
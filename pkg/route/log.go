@@ -0,0 +1,99 @@
+package route
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// maxSummaryInputs is the max number of elements of a list input such as changed files logged by TraceSummary.
+const maxSummaryInputs = 10
+
+// Traces is a list of EventTrace to be logged with zap.Array.
+type Traces []*EventTrace
+
+func (traces Traces) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return marshalTraces(enc, traces, 0)
+}
+
+// TraceSummary is Traces whose list inputs are truncated to maxSummaryInputs elements with their count.
+// A pull request can change thousands of files, so the full list makes a log line too large.
+type TraceSummary []*EventTrace
+
+func (traces TraceSummary) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return marshalTraces(enc, traces, maxSummaryInputs)
+}
+
+// marshalTraces encodes traces. If maxInputs is 0, list inputs aren't truncated.
+func marshalTraces(enc zapcore.ArrayEncoder, traces []*EventTrace, maxInputs int) error {
+	for _, trace := range traces {
+		trace := trace
+		if err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			return trace.marshal(enc, maxInputs)
+		})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (trace *EventTrace) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return trace.marshal(enc, 0)
+}
+
+func (trace *EventTrace) marshal(enc zapcore.ObjectEncoder, maxInputs int) error {
+	enc.AddString("workflow_file_name", trace.Workflow.WorkflowFileName)
+	enc.AddBool("matched", trace.Matched)
+	if len(trace.Matches) == 0 {
+		return nil
+	}
+	return enc.AddArray("matches", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, mt := range trace.Matches {
+			mt := mt
+			if err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				return mt.marshal(enc, maxInputs)
+			})); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (trace *MatchTrace) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return trace.marshal(enc, 0)
+}
+
+func (trace *MatchTrace) marshal(enc zapcore.ObjectEncoder, maxInputs int) error {
+	enc.AddBool("matched", trace.Matched)
+	if !trace.Matched && len(trace.Matchers) != 0 {
+		// the evaluation stops at the rejected matcher
+		enc.AddString("rejected_by", trace.Matchers[len(trace.Matchers)-1].Name)
+	}
+	return enc.AddArray("matchers", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, m := range trace.Matchers {
+			m := m
+			if err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				return m.marshal(enc, maxInputs)
+			})); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (trace *MatcherTrace) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return trace.marshal(enc, 0)
+}
+
+func (trace *MatcherTrace) marshal(enc zapcore.ObjectEncoder, maxInputs int) error {
+	enc.AddString("name", trace.Name)
+	enc.AddBool("matched", trace.Matched)
+	if trace.Input == nil {
+		return nil
+	}
+	if list, ok := trace.Input.([]string); ok && maxInputs > 0 && len(list) > maxInputs {
+		enc.AddInt("input_count", len(list))
+		return enc.AddReflected("input", list[:maxInputs])
+	}
+	return enc.AddReflected("input", trace.Input)
+}
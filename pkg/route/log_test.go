@@ -0,0 +1,85 @@
+package route_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/route"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTraceSummary_MarshalLogArray(t *testing.T) {
+	t.Parallel()
+	files := make([]string, 3000)
+	for i := range files {
+		files[i] = fmt.Sprintf("foo/%d.txt", i)
+	}
+	traces := []*route.EventTrace{
+		{
+			Workflow: &config.Workflow{WorkflowFileName: "test.yaml"},
+			Matches: []*route.MatchTrace{
+				{
+					Matchers: []*route.MatcherTrace{
+						{Name: "event_type", Matched: true, Input: "push"},
+						{Name: "paths", Input: files},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		marshaler  zapcore.ArrayMarshaler
+		expInputs  int
+		expCounted bool
+	}{
+		{
+			name:       "summary",
+			marshaler:  route.TraceSummary(traces),
+			expInputs:  10,
+			expCounted: true,
+		},
+		{
+			name:      "full",
+			marshaler: route.Traces(traces),
+			expInputs: 3000,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			enc := zapcore.NewMapObjectEncoder()
+			if err := enc.AddArray("routing", tt.marshaler); err != nil {
+				t.Fatal(err)
+			}
+			matcher := loggedMatcher(enc.Fields["routing"], 1)
+			inputs, _ := matcher["input"].([]string)
+			if n := len(inputs); n != tt.expInputs {
+				t.Fatalf("the number of logged inputs: wanted %d, got %d", tt.expInputs, n)
+			}
+			if tt.expCounted {
+				if diff := cmp.Diff(3000, matcher["input_count"]); diff != "" {
+					t.Fatal(diff)
+				}
+				return
+			}
+			if _, ok := matcher["input_count"]; ok {
+				t.Fatal("input_count must not be logged")
+			}
+		})
+	}
+}
+
+// loggedMatcher returns the i-th matcher of the first match of the first trace.
+func loggedMatcher(routing interface{}, i int) map[string]interface{} {
+	traces, _ := routing.([]interface{})
+	trace, _ := traces[0].(map[string]interface{})
+	matches, _ := trace["matches"].([]interface{})
+	match, _ := matches[0].(map[string]interface{})
+	matchers, _ := match["matchers"].([]interface{})
+	matcher, _ := matchers[i].(map[string]interface{})
+	return matcher
+}
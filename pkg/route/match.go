@@ -2,68 +2,152 @@ package route
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gha-trigger/gha-trigger/pkg/config"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type matchFunc func(ctx context.Context, matchConfig *config.Match, event *domain.Event) (bool, error)
 
+// inputFunc returns the value of the event compared by a matcher.
+// It's used only to explain the result, so it must not call any API.
+type inputFunc func(event *domain.Event) interface{}
+
+type matcher struct {
+	name  string
+	fn    matchFunc
+	input inputFunc
+}
+
+func getMatchers() []*matcher {
+	return []*matcher{
+		{"event_type", matchEventType, eventTypeInput},
+		{"branches", matchBranches, branchInput},
+		{"tags", matchTags, tagInput},
+		{"branches_ignore", matchBranchesIgnore, branchInput},
+		{"tags_ignore", matchTagsIgnore, tagInput},
+		// check paths lastly because api call is required
+		{"paths", matchPaths, pathInput},
+		{"paths_ignore", matchPathsIgnore, pathInput},
+		{"if", matchIf, nil},
+	}
+}
+
+// EventTrace is the result of matching an event with a config.Event.
+type EventTrace struct {
+	Workflow *config.Workflow
+	Matched  bool
+	// Matches is empty if the config.Event has no match
+	Matches []*MatchTrace
+}
+
+// MatchTrace is the result of matching an event with a config.Match.
+type MatchTrace struct {
+	Matched bool
+	// Matchers are evaluated in order and the evaluation stops at the first rejected matcher
+	Matchers []*MatcherTrace
+}
+
+type MatcherTrace struct {
+	Name    string
+	Matched bool
+	// Input is the value of the event compared by the matcher such as the branch and changed files.
+	// It's nil if the value isn't available.
+	Input interface{}
+}
+
 func Match(ctx context.Context, event *domain.Event, repo *config.Repo) ([]*config.Workflow, error) {
-	numEvents := len(repo.Events)
+	traces, err := Explain(ctx, event, repo)
+	if err != nil {
+		return nil, err
+	}
+	return MatchedWorkflows(traces), nil
+}
+
+// MatchedWorkflows returns workflows of matched traces.
+func MatchedWorkflows(traces []*EventTrace) []*config.Workflow {
 	var wfs []*config.Workflow
+	for _, trace := range traces {
+		if trace.Matched {
+			wfs = append(wfs, trace.Workflow)
+		}
+	}
+	return wfs
+}
+
+// Explain matches an event with each config.Event and returns why each workflow is run or not.
+func Explain(ctx context.Context, event *domain.Event, repo *config.Repo) ([]*EventTrace, error) {
+	numEvents := len(repo.Events)
+	traces := make([]*EventTrace, numEvents)
 	for i := 0; i < numEvents; i++ {
 		ev := repo.Events[i]
-		f, err := matchEvent(ctx, ev, event)
+		trace, err := matchEvent(ctx, ev, event)
 		if err != nil {
 			return nil, err
 		}
-		if f {
-			wfs = append(wfs, ev.Workflow)
-		}
+		traces[i] = trace
 	}
-	return wfs, nil
+	return traces, nil
 }
 
-func matchEvent(ctx context.Context, ev *config.Event, event *domain.Event) (bool, error) {
+func matchEvent(ctx context.Context, ev *config.Event, event *domain.Event) (*EventTrace, error) {
+	if ev.NeedsWorkflowOn() {
+		// the event must not match every webhook because on isn't read yet
+		return nil, fmt.Errorf("on of the workflow %s isn't read from the workflow file", ev.Workflow.WorkflowFileName)
+	}
+	trace := &EventTrace{
+		Workflow: ev.Workflow,
+	}
 	if len(ev.Matches) == 0 {
-		return true, nil
+		trace.Matched = true
+		return trace, nil
 	}
 	for _, matchConfig := range ev.Matches {
-		f, err := matchMatchConfig(ctx, matchConfig, event)
+		mt, err := matchMatchConfig(ctx, matchConfig, event)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
+		trace.Matches = append(trace.Matches, mt)
 		// OR Condition
-		if f {
-			return true, nil
+		if mt.Matched {
+			trace.Matched = true
+			return trace, nil
 		}
 	}
-	return false, nil
+	return trace, nil
 }
 
-func matchMatchConfig(ctx context.Context, matchConfig *config.Match, event *domain.Event) (bool, error) {
-	funcs := []matchFunc{
-		matchEventType,
-		matchBranches,
-		matchTags,
-		matchBranches,
-		matchBranchesIgnore,
-		matchTagsIgnore,
-		// check paths lastly because api call is required
-		matchPaths,
-		matchPathsIgnore,
-		matchIf,
-	}
-	for _, fn := range funcs {
-		f, err := fn(ctx, matchConfig, event)
+func matchMatchConfig(ctx context.Context, matchConfig *config.Match, event *domain.Event) (*MatchTrace, error) {
+	trace := &MatchTrace{}
+	for _, m := range getMatchers() {
+		f, err := runMatcher(ctx, m, matchConfig, event)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
+		mt := &MatcherTrace{
+			Name:    m.name,
+			Matched: f,
+		}
+		if m.input != nil {
+			mt.Input = m.input(event)
+		}
+		trace.Matchers = append(trace.Matchers, mt)
 		// AND condition
 		if !f {
-			return false, nil
+			return trace, nil
 		}
 	}
-	return true, nil
+	trace.Matched = true
+	return trace, nil
+}
+
+func runMatcher(ctx context.Context, m *matcher, matchConfig *config.Match, event *domain.Event) (bool, error) {
+	ctx, span := tracing.Start(ctx, "route.matcher", attribute.String("matcher", m.name))
+	f, err := m.fn(ctx, matchConfig, event)
+	span.SetAttributes(attribute.Bool("matched", f))
+	tracing.End(span, err)
+	return f, err
 }
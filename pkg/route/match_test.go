@@ -61,6 +61,25 @@ func TestMatch(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "on isn't read from the workflow file",
+			wantErr: true,
+			event: &domain.Event{
+				Payload: &domain.Payload{
+					Ref: "refs/heads/main",
+				},
+			},
+			repo: &config.Repo{
+				Events: []*config.Event{
+					{
+						OnFromWorkflow: true,
+						Workflow: &config.Workflow{
+							WorkflowFileName: "test.yaml",
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -75,7 +94,95 @@ func TestMatch(t *testing.T) {
 			if tt.wantErr {
 				t.Fatal("error must be returned")
 			}
-			if diff := cmp.Diff(workflows, tt.exp); diff != "" {
+			if diff := cmp.Diff(workflows, tt.exp, cmp.AllowUnexported(config.Workflow{})); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		wantErr bool
+		exp     []*route.EventTrace
+		event   *domain.Event
+		repo    *config.Repo
+	}{
+		{
+			name: "rejected by branches",
+			exp: []*route.EventTrace{
+				{
+					Workflow: &config.Workflow{
+						WorkflowFileName: "test.yaml",
+					},
+					Matches: []*route.MatchTrace{
+						{
+							Matchers: []*route.MatcherTrace{
+								{
+									Name:    "event_type",
+									Matched: true,
+									Input:   "pull_request:opened",
+								},
+								{
+									Name:  "branches",
+									Input: "develop",
+								},
+							},
+						},
+					},
+				},
+			},
+			event: &domain.Event{
+				Type: "pull_request",
+				Payload: &domain.Payload{
+					Action: "opened",
+					Ref:    "refs/heads/develop",
+				},
+			},
+			repo: &config.Repo{
+				Events: []*config.Event{
+					{
+						Matches: []*config.Match{
+							{
+								Events: []*config.EventType{
+									{
+										Name: "pull_request",
+									},
+								},
+								Branches: []*config.StringMatch{
+									{
+										Type:  "equal",
+										Value: "main",
+									},
+								},
+							},
+						},
+						Workflow: &config.Workflow{
+							WorkflowFileName: "test.yaml",
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			traces, err := route.Explain(ctx, tt.event, tt.repo)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(tt.exp, traces, cmp.AllowUnexported(config.Workflow{})); diff != "" {
 				t.Fatal(diff)
 			}
 		})
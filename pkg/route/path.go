@@ -15,6 +15,9 @@ func matchPaths(ctx context.Context, matchConfig *config.Match, event *domain.Ev
 	if err != nil {
 		return false, err
 	}
+	if event.ChangedFilesUnknown {
+		return matchConfig.ChangedFilesUnknown != config.ChangedFilesUnknownFailClosed, nil
+	}
 	for _, changedFile := range changedFiles {
 		for _, p := range matchConfig.Paths {
 			f, err := p.Match(changedFile)
@@ -37,6 +40,9 @@ func matchPathsIgnore(ctx context.Context, matchConfig *config.Match, event *dom
 	if err != nil {
 		return false, err
 	}
+	if event.ChangedFilesUnknown {
+		return matchConfig.ChangedFilesUnknown != config.ChangedFilesUnknownFailClosed, nil
+	}
 	for _, changedFile := range changedFiles {
 		f, err := matchPath(changedFile, matchConfig.PathsIgnore)
 		if err != nil {
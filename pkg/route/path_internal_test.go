@@ -0,0 +1,113 @@
+package route
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+)
+
+func Test_matchPaths(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		wantErr     bool
+		exp         bool
+		matchConfig *config.Match
+		event       *domain.Event
+	}{
+		{
+			name:        "no paths",
+			matchConfig: &config.Match{},
+			exp:         true,
+		},
+		{
+			name: "match",
+			exp:  true,
+			matchConfig: &config.Match{
+				Paths: []*config.StringMatch{
+					{
+						Type:  "prefix",
+						Value: "docs/",
+					},
+				},
+			},
+			event: &domain.Event{
+				ChangedFileObjs: []*github.CommitFile{},
+				ChangedFiles:    []string{"main.go", "docs/README.md"},
+			},
+		},
+		{
+			name: "not match",
+			matchConfig: &config.Match{
+				Paths: []*config.StringMatch{
+					{
+						Type:  "prefix",
+						Value: "docs/",
+					},
+				},
+			},
+			event: &domain.Event{
+				ChangedFileObjs: []*github.CommitFile{},
+				ChangedFiles:    []string{"main.go"},
+			},
+		},
+		{
+			name: "changed files are unknown (fail_open)",
+			exp:  true,
+			matchConfig: &config.Match{
+				Paths: []*config.StringMatch{
+					{
+						Type:  "prefix",
+						Value: "docs/",
+					},
+				},
+				ChangedFilesUnknown: "fail_open",
+			},
+			event: &domain.Event{
+				ChangedFileObjs:     []*github.CommitFile{},
+				ChangedFiles:        []string{"main.go"},
+				ChangedFilesUnknown: true,
+			},
+		},
+		{
+			name: "changed files are unknown (fail_closed)",
+			matchConfig: &config.Match{
+				Paths: []*config.StringMatch{
+					{
+						Type:  "prefix",
+						Value: "docs/",
+					},
+				},
+				ChangedFilesUnknown: "fail_closed",
+			},
+			event: &domain.Event{
+				ChangedFileObjs:     []*github.CommitFile{},
+				ChangedFiles:        []string{"docs/README.md"},
+				ChangedFilesUnknown: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := matchPaths(ctx, tt.matchConfig, tt.event)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if f != tt.exp {
+				t.Fatalf("wanted %v, got %v", tt.exp, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package route_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/route"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestExplain_span(t *testing.T) { //nolint:paralleltest
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	})
+	event := &domain.Event{
+		Type: "push",
+		Payload: &domain.Payload{
+			Ref: "refs/heads/main",
+		},
+	}
+	repo := &config.Repo{
+		Events: []*config.Event{
+			{
+				Matches: []*config.Match{
+					{
+						Branches: []*config.StringMatch{
+							{
+								Type:  "equal",
+								Value: "develop",
+							},
+						},
+					},
+				},
+				Workflow: &config.Workflow{
+					WorkflowFileName: "test.yaml",
+				},
+			},
+		},
+	}
+	if _, err := route.Explain(context.Background(), event, repo); err != nil {
+		t.Fatal(err)
+	}
+	matchers := []string{}
+	for _, span := range recorder.Ended() {
+		for _, attr := range span.Attributes() {
+			if attr.Key == "matcher" {
+				matchers = append(matchers, attr.Value.AsString())
+			}
+		}
+	}
+	if diff := cmp.Diff([]string{"event_type", "branches"}, matchers); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,67 @@
+package runworkflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+)
+
+var errRefEmpty = errors.New("the workflow ref is empty")
+
+// resolveRef renders the workflow ref with the event.
+// If the rendered ref is empty or doesn't exist in the repository, RefFallback is used.
+func resolveRef(ctx context.Context, ev *domain.Event, workflow *config.Workflow, repoOwner, repoName string) (string, error) {
+	ref, err := workflow.RenderRef(getRefParam(ev))
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	fallback := workflow.RefFallback
+	if ref == "" {
+		if fallback == "" {
+			return "", errRefEmpty
+		}
+		return fallback, nil
+	}
+	if fallback == "" || ref == fallback {
+		return ref, nil
+	}
+	f, err := workflow.GitHub.RefExists(ctx, repoOwner, repoName, ref)
+	if err != nil {
+		return "", fmt.Errorf("check if the ref exists: %w", err)
+	}
+	if !f {
+		return fallback, nil
+	}
+	return ref, nil
+}
+
+func getRefParam(ev *domain.Event) *config.RefParam {
+	param := &config.RefParam{
+		Event:     ev.Raw,
+		EventName: ev.Type,
+	}
+	payload := ev.Payload
+	if payload == nil {
+		return param
+	}
+	switch {
+	case payload.PullRequest != nil:
+		pr := payload.PullRequest
+		param.BaseRef = pr.GetBase().GetRef()
+		param.HeadRef = pr.GetHead().GetRef()
+		labels := make([]string, len(pr.Labels))
+		for i, label := range pr.Labels {
+			labels[i] = label.GetName()
+		}
+		param.Labels = labels
+	case payload.MergeGroup != nil:
+		param.BaseRef = strings.TrimPrefix(payload.MergeGroup.GetBaseRef(), "refs/heads/")
+	default:
+		param.BaseRef = strings.TrimPrefix(payload.Ref, "refs/heads/")
+	}
+	return param
+}
@@ -0,0 +1,129 @@
+package runworkflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+)
+
+type refClient struct {
+	refs map[string]struct{}
+}
+
+func (client *refClient) RunWorkflow(ctx context.Context, owner, repo, workflowFileName string, event github.CreateWorkflowDispatchEventRequest) (*github.Response, error) {
+	return nil, nil
+}
+
+func (client *refClient) RefExists(ctx context.Context, owner, repo, ref string) (bool, error) {
+	_, ok := client.refs[ref]
+	return ok, nil
+}
+
+func Test_resolveRef(t *testing.T) {
+	t.Parallel()
+	gh := &refClient{
+		refs: map[string]struct{}{
+			"main":      {},
+			"release-1": {},
+		},
+	}
+	prEvent := &domain.Event{
+		Type: "pull_request",
+		Payload: &domain.Payload{
+			PullRequest: &github.PullRequest{
+				Base: &github.PullRequestBranch{
+					Ref: util.StrP("release-1"),
+				},
+				Head: &github.PullRequestBranch{
+					Ref: util.StrP("feature"),
+				},
+				Labels: []*github.Label{
+					{
+						Name: util.StrP("ci-ref:feature"),
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		wantErr  bool
+		exp      string
+		ev       *domain.Event
+		workflow *config.Workflow
+	}{
+		{
+			name: "fixed",
+			exp:  "main",
+			ev:   prEvent,
+			workflow: &config.Workflow{
+				Ref: "main",
+			},
+		},
+		{
+			name: "base branch",
+			exp:  "release-1",
+			ev:   prEvent,
+			workflow: &config.Workflow{
+				Ref:         "{{ .BaseRef }}",
+				RefFallback: "main",
+			},
+		},
+		{
+			name: "label doesn't exist in the repository",
+			exp:  "main",
+			ev:   prEvent,
+			workflow: &config.Workflow{
+				Ref:         `{{ labelSuffix .Labels "ci-ref:" }}`,
+				RefFallback: "main",
+			},
+		},
+		{
+			name: "empty",
+			exp:  "main",
+			ev: &domain.Event{
+				Type:    "push",
+				Payload: &domain.Payload{},
+			},
+			workflow: &config.Workflow{
+				Ref:         "{{ .BaseRef }}",
+				RefFallback: "main",
+			},
+		},
+		{
+			name:    "empty without fallback",
+			wantErr: true,
+			ev: &domain.Event{
+				Type:    "push",
+				Payload: &domain.Payload{},
+			},
+			workflow: &config.Workflow{
+				Ref: "{{ .BaseRef }}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.workflow.GitHub = gh
+			ref, err := resolveRef(context.Background(), tt.ev, tt.workflow, "suzuki-shunsuke", "foo-ci")
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if ref != tt.exp {
+				t.Fatalf("wanted %s, got %s", tt.exp, ref)
+			}
+		})
+	}
+}
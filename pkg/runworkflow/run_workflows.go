@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/metrics"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
 	"go.uber.org/zap"
 )
 
@@ -19,14 +23,25 @@ type WorkflowInput struct {
 	EventName    string               `json:"event_name"`
 	ChangedFiles []*github.CommitFile `json:"changed_files,omitempty"`
 	PullRequest  *github.PullRequest  `json:"pull_request,omitempty"`
+	// SHA is the commit SHA to be tested.
+	// It's set for merge_group events and pull requests tested against the head commit.
+	SHA string `json:"sha,omitempty"`
+	// CommitType is either "merge" or "head" for pull requests.
+	// "head" means the head commit is tested instead of the merge commit.
+	CommitType string `json:"commit_type,omitempty"`
 }
 
-func getWorkflowInput(ev *domain.Event) (map[string]interface{}, error) {
+func getWorkflowInput(ev *domain.Event, target *commitTarget) (map[string]interface{}, error) {
 	input := &WorkflowInput{
 		Event:        ev.Raw,
 		EventName:    ev.Type,
 		ChangedFiles: ev.ChangedFileObjs,
 		PullRequest:  ev.Payload.PullRequest,
+		SHA:          target.sha,
+		CommitType:   target.commitType,
+	}
+	if mg := ev.Payload.MergeGroup; mg != nil {
+		input.SHA = mg.GetHeadSHA()
 	}
 
 	b, err := json.Marshal(input)
@@ -38,7 +53,11 @@ func getWorkflowInput(ev *domain.Event) (map[string]interface{}, error) {
 	}, nil
 }
 
-func RunWorkflows(ctx context.Context, logger *zap.Logger, gh GitHubPRClient, ev *domain.Event, repoCfg *config.Repo, workflows []*config.Workflow) error {
+// ErrWorkflowsFailed is returned if some workflows fail to be run.
+// Each failure has already been logged and recorded to the audit sink.
+var ErrWorkflowsFailed = errors.New("some workflows failed to be run")
+
+func RunWorkflows(ctx context.Context, logger *zap.Logger, gh GitHubPRClient, sink audit.Sink, ev *domain.Event, repoCfg *config.Repo, workflows []*config.Workflow) error {
 	if len(workflows) == 0 {
 		logger.Info("no workflow is run")
 		return nil //nolint:nilnil
@@ -47,60 +66,255 @@ func RunWorkflows(ctx context.Context, logger *zap.Logger, gh GitHubPRClient, ev
 	repo := ev.Payload.Repo
 	repoOwner := repo.GetOwner().GetLogin()
 	repoName := repo.GetName()
+	target := &commitTarget{}
 	if pr := ev.Payload.PullRequest; pr != nil {
 		// https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#pull_request
 		// sha: Last merge commit on the GITHUB_REF branch
 		// ref: PR merge branch refs/pull/:prNumber/merge
-		pr, err := waitPRMergeable(ctx, gh, pr, repoOwner, repoName)
+		t, err := getPRCommitTarget(ctx, logger, gh, pr, repoOwner, repoName, repoCfg.MergeablePolling)
 		if err != nil {
-			return fmt.Errorf("wait until pull request's mergeable becomes not nil: %w", err)
+			return err
 		}
-		if !pr.GetMergeable() {
-			logger.Warn("pull_request isn't mergeable")
+		if t == nil {
 			return nil
 		}
-		ev.Payload.PullRequest = pr
+		target = t
+		ev.Payload.PullRequest = t.pr
+		if t.notMergeable {
+			workflows = filterWorkflowsRunHeadIfNotMergeable(workflows)
+			if len(workflows) == 0 {
+				logger.Warn("pull_request isn't mergeable")
+				return nil
+			}
+			logger.Warn("pull_request isn't mergeable, so workflows are run against the head commit")
+		}
+	}
+
+	inputs, err := getWorkflowInput(ev, target)
+	if err != nil {
+		return err
 	}
 
-	inputs, err := getWorkflowInput(ev)
+	inputsHash, err := audit.HashInputs(inputs)
 	if err != nil {
 		return err
 	}
 
 	numWorkflows := len(workflows)
+	failed := 0
+	var dispatched []*config.Workflow
 	for i := 0; i < numWorkflows; i++ {
 		workflow := workflows[i]
 		// Run GitHub Actions Workflow
+		wfRepoOwner := workflow.GetRepoOwner(repoCfg)
+		wfRepoName := workflow.GetRepoName(repoCfg)
 		logger := logger.With(
-			zap.String("workflow_repo_owner", repoCfg.RepoOwner),
-			zap.String("workflow_repo_name", repoCfg.CIRepoName),
+			zap.String("workflow_repo_owner", wfRepoOwner),
+			zap.String("workflow_repo_name", wfRepoName),
 			zap.String("workflow_file_name", workflow.WorkflowFileName),
 			zap.String("workflow_ref", workflow.Ref))
+		rec := newAuditRecord(ev, repoCfg, workflow, target, wfRepoOwner+"/"+wfRepoName, inputsHash)
+		ref, err := resolveRef(ctx, ev, workflow, wfRepoOwner, wfRepoName)
+		if err != nil {
+			logger.Error("resolve the workflow ref", zap.Error(err))
+			failed++
+			rec.SetResult(err)
+			audit.Write(ctx, logger, sink, rec)
+			continue
+		}
+		rec.Ref = ref
+		if ref != workflow.Ref {
+			logger = logger.With(zap.String("workflow_resolved_ref", ref))
+		}
+		if repoCfg.DryRun {
+			logger.Info("dry run: a GitHub Actions Workflow would be run", zap.Any("workflow_inputs", inputs))
+			rec.Result = audit.ResultDryRun
+			audit.Write(ctx, logger, sink, rec)
+			continue
+		}
 		logger.Info("running a GitHub Actions Workflow")
-		_, err := workflow.GitHub.RunWorkflow(ctx, repoCfg.RepoOwner, repoCfg.CIRepoName, workflow.WorkflowFileName, github.CreateWorkflowDispatchEventRequest{
-			Ref:    workflow.Ref,
+		_, err = workflow.GitHub.RunWorkflow(ctx, wfRepoOwner, wfRepoName, workflow.WorkflowFileName, github.CreateWorkflowDispatchEventRequest{
+			Ref:    ref,
 			Inputs: inputs,
 		})
+		metrics.RecordDispatch(ctx, wfRepoOwner+"/"+wfRepoName, workflow.WorkflowFileName, err)
 		if err != nil {
 			logger.Error(
 				"create a workflow dispatch event by file name",
 				zap.Error(err))
+			failed++
+		} else {
+			dispatched = append(dispatched, workflow)
+		}
+		rec.SetResult(err)
+		audit.Write(ctx, logger, sink, rec)
+	}
+	if target.notMergeable && len(dispatched) != 0 {
+		if gh.IsRateLimitLow() {
+			// the comment is non-essential, so it's skipped to keep the rate limit
+			logger.Warn("a comment to the pull request isn't posted because the rate limit of GitHub API is low")
+		} else if err := notifyNotMergeable(ctx, gh, ev.Payload.PullRequest, repoOwner, repoName, dispatched); err != nil {
+			logger.Error("post a comment to the pull request", zap.Error(err))
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d workflows: %w", failed, numWorkflows, ErrWorkflowsFailed)
+	}
+	return nil
+}
+
+func newAuditRecord(ev *domain.Event, repoCfg *config.Repo, workflow *config.Workflow, target *commitTarget, targetRepo, inputsHash string) *audit.Record {
+	rec := audit.NewRecord(ev, audit.ActionWorkflowDispatch)
+	if target.sha != "" {
+		rec.SourceSHA = target.sha
+	}
+	rec.MatchedEvent = newMatchedEvent(repoCfg, workflow)
+	rec.TargetRepo = targetRepo
+	rec.Workflow = workflow.WorkflowFileName
+	rec.InputsHash = inputsHash
+	return rec
+}
+
+// newMatchedEvent returns the config.Event whose workflow is run.
+// It returns nil for schedules.
+func newMatchedEvent(repoCfg *config.Repo, workflow *config.Workflow) *audit.MatchedEvent {
+	for _, ev := range repoCfg.Events {
+		if ev.Workflow != workflow {
+			continue
+		}
+		var evTypes []string
+		seen := map[string]struct{}{}
+		for _, match := range ev.Matches {
+			for _, evType := range match.Events {
+				if _, ok := seen[evType.Name]; ok {
+					continue
+				}
+				seen[evType.Name] = struct{}{}
+				evTypes = append(evTypes, evType.Name)
+			}
+		}
+		return &audit.MatchedEvent{
+			Events:         evTypes,
+			Ref:            workflow.Ref,
+			RefFallback:    workflow.RefFallback,
+			OnFromWorkflow: ev.OnFromWorkflow,
 		}
 	}
 	return nil
 }
 
+const (
+	commitTypeMerge = "merge"
+	commitTypeHead  = "head"
+)
+
+type commitTarget struct {
+	pr           *github.PullRequest
+	sha          string
+	commitType   string
+	notMergeable bool
+}
+
+// getPRCommitTarget waits until the pull request's mergeable is computed and decides the commit to be tested.
+// If workflows shouldn't be run, nil is returned.
+func getPRCommitTarget(ctx context.Context, logger *zap.Logger, gh GitHubPRClient, pr *github.PullRequest, repoOwner, repoName string, polling *config.MergeablePolling) (*commitTarget, error) {
+	start := time.Now()
+	p, err := waitPRMergeable(ctx, gh, pr, repoOwner, repoName, polling)
+	metrics.RecordMergeablePoll(ctx, time.Since(start), err)
+	switch {
+	case errors.Is(err, errMergeableTimeout):
+		switch polling.GetOnTimeout() {
+		case config.OnTimeoutSkip:
+			logger.Warn("workflows aren't run because pull request's mergeable isn't computed in time",
+				zap.Duration("mergeable_polling_max_wait", polling.GetMaxWait()))
+			return nil, nil
+		case config.OnTimeoutHead:
+			logger.Warn("workflows are run against the head commit because pull request's mergeable isn't computed in time",
+				zap.Duration("mergeable_polling_max_wait", polling.GetMaxWait()))
+			return &commitTarget{
+				pr:         pr,
+				sha:        pr.GetHead().GetSHA(),
+				commitType: commitTypeHead,
+			}, nil
+		default:
+			return nil, fmt.Errorf("wait until pull request's mergeable becomes not nil: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("wait until pull request's mergeable becomes not nil: %w", err)
+	case !p.GetMergeable():
+		return &commitTarget{
+			pr:           p,
+			sha:          p.GetHead().GetSHA(),
+			commitType:   commitTypeHead,
+			notMergeable: true,
+		}, nil
+	default:
+		return &commitTarget{
+			pr:         p,
+			commitType: commitTypeMerge,
+		}, nil
+	}
+}
+
+func filterWorkflowsRunHeadIfNotMergeable(workflows []*config.Workflow) []*config.Workflow {
+	var wfs []*config.Workflow
+	for _, wf := range workflows {
+		if wf.RunHeadIfNotMergeable {
+			wfs = append(wfs, wf)
+		}
+	}
+	return wfs
+}
+
+// notMergeableMarker identifies the comment of notifyNotMergeable.
+// The comment is updated instead of posting a new one at every event of the pull request.
+const notMergeableMarker = "<!-- gha-trigger:not-mergeable -->"
+
+// notifyNotMergeable posts or updates the comment listing workflows run against the head commit.
+func notifyNotMergeable(ctx context.Context, gh GitHubPRClient, pr *github.PullRequest, repoOwner, repoName string, workflows []*config.Workflow) error {
+	names := make([]string, len(workflows))
+	for i, wf := range workflows {
+		names[i] = "- " + wf.WorkflowFileName
+	}
+	body := fmt.Sprintf(`This pull request isn't mergeable, so the following workflows are run against the head commit %s instead of the merge commit.
+Please resolve conflicts to test the merge commit.
+
+%s`, pr.GetHead().GetSHA(), strings.Join(names, "\n"))
+	_, err := gh.UpsertIssueComment(ctx, repoOwner, repoName, pr.GetNumber(), notMergeableMarker, body)
+	return err //nolint:wrapcheck
+}
+
 type GitHubPRClient interface {
 	GetPR(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error)
+	UpsertIssueComment(ctx context.Context, owner, repo string, number int, marker, body string) (*github.Response, error)
+	IsRateLimitLow() bool
 }
 
-func waitPRMergeable(ctx context.Context, gh GitHubPRClient, pr *github.PullRequest, repoOwner, repoName string) (*github.PullRequest, error) {
-	for i := 0; i < 10; i++ {
-		if m := pr.Mergeable; m != nil {
-			return pr, nil
+var errMergeableTimeout = errors.New("pull request's mergeable isn't computed in time")
+
+// waitPRMergeable polls a pull request until its mergeable is computed.
+// The polling interval is doubled up to the max interval, and errMergeableTimeout is returned after the max wait.
+func waitPRMergeable(ctx context.Context, gh GitHubPRClient, pr *github.PullRequest, repoOwner, repoName string, polling *config.MergeablePolling) (*github.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "waitPRMergeable")
+	p, err := pollPRMergeable(ctx, gh, pr, repoOwner, repoName, polling)
+	tracing.End(span, err)
+	return p, err
+}
+
+func pollPRMergeable(ctx context.Context, gh GitHubPRClient, pr *github.PullRequest, repoOwner, repoName string, polling *config.MergeablePolling) (*github.PullRequest, error) {
+	deadline := time.Now().Add(polling.GetMaxWait())
+	interval := polling.GetInterval()
+	maxInterval := polling.GetMaxInterval()
+	for pr.Mergeable == nil {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, errMergeableTimeout
+		}
+		if interval > remaining {
+			interval = remaining
 		}
 		// polling
-		if err := wait(ctx, 10*time.Second); err != nil { //nolint:gomnd
+		if err := wait(ctx, interval); err != nil {
 			return nil, err
 		}
 		p, _, err := gh.GetPR(ctx, repoOwner, repoName, pr.GetNumber())
@@ -108,8 +322,12 @@ func waitPRMergeable(ctx context.Context, gh GitHubPRClient, pr *github.PullRequ
 			return nil, err
 		}
 		pr = p
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
 	}
-	return nil, errors.New("timeout error")
+	return pr, nil
 }
 
 func wait(ctx context.Context, duration time.Duration) error {
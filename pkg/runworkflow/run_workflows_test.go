@@ -2,26 +2,42 @@ package runworkflow_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
 	"github.com/gha-trigger/gha-trigger/pkg/github"
 	"github.com/gha-trigger/gha-trigger/pkg/runworkflow"
 	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
 )
 
 type githubPRClient struct {
-	pr   *github.PullRequest
-	resp *github.Response
-	err  error
+	pr           *github.PullRequest
+	resp         *github.Response
+	err          error
+	rateLimitLow bool
+	commented    bool
 }
 
 func (client *githubPRClient) GetPR(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error) {
 	return client.pr, client.resp, client.err
 }
 
+func (client *githubPRClient) UpsertIssueComment(ctx context.Context, owner, repo string, number int, marker, body string) (*github.Response, error) {
+	client.commented = true
+	return client.resp, client.err
+}
+
+func (client *githubPRClient) IsRateLimitLow() bool {
+	return client.rateLimitLow
+}
+
 type githubWorkflowClient struct {
 	resp *github.Response
 	err  error
@@ -31,6 +47,19 @@ func (client *githubWorkflowClient) RunWorkflow(ctx context.Context, owner, repo
 	return client.resp, client.err
 }
 
+func (client *githubWorkflowClient) RefExists(ctx context.Context, owner, repo, ref string) (bool, error) {
+	return true, client.err
+}
+
+type auditSink struct {
+	recs []*audit.Record
+}
+
+func (sink *auditSink) Write(ctx context.Context, rec *audit.Record) error {
+	sink.recs = append(sink.recs, rec)
+	return nil
+}
+
 func TestRunWorkflows(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -40,6 +69,7 @@ func TestRunWorkflows(t *testing.T) {
 		repoCfg   *config.Repo
 		workflows []*config.Workflow
 		gh        runworkflow.GitHubPRClient
+		commented bool
 	}{
 		{
 			name: "pull_request",
@@ -73,6 +103,293 @@ func TestRunWorkflows(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "mergeable timeout (fail)",
+			wantErr: true,
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+				MergeablePolling: &config.MergeablePolling{
+					Interval:  time.Millisecond,
+					MaxWait:   10 * time.Millisecond,
+					OnTimeout: "fail",
+				},
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName: "test_pull_request.yaml",
+					Ref:              "pull_request",
+					GitHub:           &githubWorkflowClient{},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{},
+			},
+		},
+		{
+			name:    "mergeable timeout (skip)",
+			wantErr: false,
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+				MergeablePolling: &config.MergeablePolling{
+					Interval:  time.Millisecond,
+					MaxWait:   10 * time.Millisecond,
+					OnTimeout: "skip",
+				},
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName: "test_pull_request.yaml",
+					Ref:              "pull_request",
+					GitHub:           &githubWorkflowClient{},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{},
+			},
+		},
+		{
+			name:    "mergeable timeout (head)",
+			wantErr: false,
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+				MergeablePolling: &config.MergeablePolling{
+					Interval:  time.Millisecond,
+					MaxWait:   10 * time.Millisecond,
+					OnTimeout: "head",
+				},
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName: "test_pull_request.yaml",
+					Ref:              "pull_request",
+					GitHub:           &githubWorkflowClient{},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{},
+			},
+		},
+		{
+			name: "not mergeable",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName:      "test_pull_request.yaml",
+					Ref:                   "pull_request",
+					RunHeadIfNotMergeable: false,
+					GitHub:                &githubWorkflowClient{},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{
+					Mergeable: util.BoolP(false),
+				},
+			},
+		},
+		{
+			name: "not mergeable (run head)",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName:      "test_pull_request.yaml",
+					Ref:                   "pull_request",
+					RunHeadIfNotMergeable: true,
+					GitHub:                &githubWorkflowClient{},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{
+					Mergeable: util.BoolP(false),
+				},
+			},
+			commented: true,
+		},
+		{
+			name: "not mergeable (rate limit is low)",
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName:      "test_pull_request.yaml",
+					Ref:                   "pull_request",
+					RunHeadIfNotMergeable: true,
+					GitHub:                &githubWorkflowClient{},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{
+					Mergeable: util.BoolP(false),
+				},
+				rateLimitLow: true,
+			},
+		},
+		{
+			name:    "not mergeable (dispatch failure)",
+			wantErr: true,
+			ev: &domain.Event{
+				Type: "pull_request",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						Name: util.StrP("example-main"),
+						Owner: &github.User{
+							Login: util.StrP("gha-trigger"),
+						},
+					},
+					PullRequest: &github.PullRequest{},
+				},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName:      "test_pull_request.yaml",
+					Ref:                   "pull_request",
+					RunHeadIfNotMergeable: true,
+					GitHub: &githubWorkflowClient{
+						err: errors.New("server error"),
+					},
+				},
+			},
+			gh: &githubPRClient{
+				pr: &github.PullRequest{
+					Mergeable: util.BoolP(false),
+				},
+			},
+		},
+		{
+			name:    "dispatch failure",
+			wantErr: true,
+			ev: &domain.Event{
+				Type:    "push",
+				Raw:     map[string]interface{}{},
+				Payload: &domain.Payload{},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+			},
+			workflows: []*config.Workflow{
+				{
+					WorkflowFileName: "test.yaml",
+					Ref:              "main",
+					GitHub: &githubWorkflowClient{
+						err: errors.New("server error"),
+					},
+				},
+			},
+		},
+		{
+			name: "dry run",
+			ev: &domain.Event{
+				Type:    "push",
+				Raw:     map[string]interface{}{},
+				Payload: &domain.Payload{},
+			},
+			repoCfg: &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+				DryRun:     true,
+			},
+			workflows: []*config.Workflow{
+				{
+					// GitHub is nil because the workflow isn't run
+					WorkflowFileName: "test.yaml",
+					Ref:              "main",
+				},
+			},
+		},
 	}
 	ctx := context.Background()
 	logger, _ := zap.NewProduction()
@@ -80,7 +397,11 @@ func TestRunWorkflows(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			if err := runworkflow.RunWorkflows(ctx, logger, tt.gh, tt.ev, tt.repoCfg, tt.workflows); err != nil {
+			err := runworkflow.RunWorkflows(ctx, logger, tt.gh, nil, tt.ev, tt.repoCfg, tt.workflows)
+			if gh, ok := tt.gh.(*githubPRClient); ok && gh.commented != tt.commented {
+				t.Fatalf("commented: wanted %v, got %v", tt.commented, gh.commented)
+			}
+			if err != nil {
 				if tt.wantErr {
 					return
 				}
@@ -92,3 +413,159 @@ func TestRunWorkflows(t *testing.T) {
 		})
 	}
 }
+
+func TestRunWorkflows_audit(t *testing.T) {
+	t.Parallel()
+	newWorkflows := func() []*config.Workflow {
+		return []*config.Workflow{
+			{
+				WorkflowFileName: "test.yaml",
+				Ref:              "main",
+				GitHub:           &githubWorkflowClient{},
+			},
+			{
+				WorkflowFileName: "lint.yaml",
+				Ref:              "main",
+				GitHub: &githubWorkflowClient{
+					err: errors.New("server error"),
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		dryRun  bool
+		wantErr bool
+		exp     []*audit.Record
+	}{
+		{
+			name:    "normal",
+			wantErr: true,
+			exp: []*audit.Record{
+				{
+					Action:     audit.ActionWorkflowDispatch,
+					Event:      "push",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					SourceSHA:  "abc",
+					MatchedEvent: &audit.MatchedEvent{
+						Events: []string{"push"},
+						Ref:    "main",
+					},
+					TargetRepo: "gha-trigger/example-ci",
+					Workflow:   "test.yaml",
+					Ref:        "main",
+					Result:     audit.ResultSuccess,
+				},
+				{
+					Action:     audit.ActionWorkflowDispatch,
+					Event:      "push",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					SourceSHA:  "abc",
+					MatchedEvent: &audit.MatchedEvent{
+						Events:         []string{"push", "pull_request"},
+						Ref:            "main",
+						OnFromWorkflow: true,
+					},
+					TargetRepo: "gha-trigger/example-ci",
+					Workflow:   "lint.yaml",
+					Ref:        "main",
+					Result:     audit.ResultFailure,
+					Error:      "server error",
+				},
+			},
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+			exp: []*audit.Record{
+				{
+					Action:     audit.ActionWorkflowDispatch,
+					Event:      "push",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					SourceSHA:  "abc",
+					MatchedEvent: &audit.MatchedEvent{
+						Events: []string{"push"},
+						Ref:    "main",
+					},
+					TargetRepo: "gha-trigger/example-ci",
+					Workflow:   "test.yaml",
+					Ref:        "main",
+					Result:     audit.ResultDryRun,
+				},
+				{
+					Action:     audit.ActionWorkflowDispatch,
+					Event:      "push",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					SourceSHA:  "abc",
+					MatchedEvent: &audit.MatchedEvent{
+						Events:         []string{"push", "pull_request"},
+						Ref:            "main",
+						OnFromWorkflow: true,
+					},
+					TargetRepo: "gha-trigger/example-ci",
+					Workflow:   "lint.yaml",
+					Ref:        "main",
+					Result:     audit.ResultDryRun,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	logger, _ := zap.NewProduction()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			workflows := newWorkflows()
+			repoCfg := &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+				DryRun:     tt.dryRun,
+				Events: []*config.Event{
+					{
+						Matches: []*config.Match{
+							{Events: []*config.EventType{{Name: "push"}}},
+						},
+						Workflow: workflows[0],
+					},
+					{
+						Matches: []*config.Match{
+							{Events: []*config.EventType{{Name: "push"}}},
+							{Events: []*config.EventType{{Name: "push"}, {Name: "pull_request"}}},
+						},
+						OnFromWorkflow: true,
+						Workflow:       workflows[1],
+					},
+				},
+			}
+			ev := &domain.Event{
+				Type: "push",
+				Raw:  map[string]interface{}{},
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						FullName: util.StrP("gha-trigger/example"),
+					},
+					After: "abc",
+					Sender: &github.User{
+						Login: util.StrP("octocat"),
+					},
+				},
+			}
+			sink := &auditSink{}
+			err := runworkflow.RunWorkflows(ctx, logger, nil, sink, ev, repoCfg, workflows)
+			if err != nil && !tt.wantErr {
+				t.Fatal(err)
+			}
+			if err == nil && tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(tt.exp, sink.recs, cmpopts.IgnoreFields(audit.Record{}, "Time", "InputsHash")); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
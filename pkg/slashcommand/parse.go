@@ -1,6 +1,7 @@
 package slashcommand
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gha-trigger/gha-trigger/pkg/util"
@@ -20,3 +21,17 @@ func parseIDs(words []string) ([]int64, error) {
 	}
 	return ids, nil
 }
+
+var (
+	errRunIDRequired = errors.New("workflow run id is required")
+	errJobIDRequired = errors.New("job id is required")
+)
+
+// checkFailures returns an error if some API calls failed.
+// The detail of each failure is logged.
+func checkFailures(failed int) error {
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d API calls failed", failed)
+}
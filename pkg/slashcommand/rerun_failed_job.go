@@ -11,21 +11,22 @@ type FailedJobsRerunner interface {
 	RerunFailedJobs(ctx context.Context, owner, repo string, runID int64) (*github.Response, error)
 }
 
-func rerunFailedJobs(ctx context.Context, logger *zap.Logger, gh FailedJobsRerunner, owner, repo string, words []string) {
+func rerunFailedJobs(ctx context.Context, logger *zap.Logger, gh FailedJobsRerunner, owner, repo string, words []string) ([]int64, error) {
 	// /rerun-failed-job <workflow id> [<workflow id> ...]
 	if len(words) == 0 { //nolint:gomnd
 		// TODO send notification to issue or pr
 		logger.Warn("workflow id is required for /rerun-failed-job")
-		return
+		return nil, errRunIDRequired
 	}
 
 	ids, err := parseIDs(words)
 	if err != nil {
 		logger.Warn("parse a workflow run id as int64", zap.Error(err))
 		// TODO send notification to issue or pr
-		return
+		return nil, err
 	}
 
+	affected := make([]int64, 0, len(ids))
 	for _, runID := range ids {
 		logger := logger.With(zap.Int64("workflow_run_id", runID))
 		logger.Info("rerunning failed jobs")
@@ -35,6 +36,9 @@ func rerunFailedJobs(ctx context.Context, logger *zap.Logger, gh FailedJobsRerun
 				"rerun failed jobs", zap.Error(err),
 				zap.Int("status_code", res.StatusCode),
 			)
+			continue
 		}
+		affected = append(affected, runID)
 	}
+	return affected, checkFailures(len(ids) - len(affected))
 }
@@ -4,35 +4,75 @@ import (
 	"context"
 	"strings"
 
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
 	"github.com/gha-trigger/gha-trigger/pkg/config"
 	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/tracing"
 	"go.uber.org/zap"
 )
 
-func Handle(ctx context.Context, logger *zap.Logger, repoCfg *config.Repo, ev *domain.Event) bool {
+// Handle runs a slash command in the comment and records it to the audit sink.
+// It returns true if the comment is a slash command.
+func Handle(ctx context.Context, logger *zap.Logger, sink audit.Sink, repoCfg *config.Repo, ev *domain.Event) bool {
+	ctx, span := tracing.Start(ctx, "slashcommand.Handle")
+	handled, err := handle(ctx, logger, sink, repoCfg, ev)
+	tracing.End(span, err)
+	return handled
+}
+
+// handle returns the error of the slash command only to record it to the span.
+// The error has already been logged and recorded to the audit sink.
+func handle(ctx context.Context, logger *zap.Logger, sink audit.Sink, repoCfg *config.Repo, ev *domain.Event) (bool, error) {
 	if ev.Type != "issue_comment" {
-		return false
+		return false, nil
 	}
 	cmt := ev.Payload.Comment
 	if strings.Contains(cmt.GetHTMLURL(), "/issue/") {
-		return false
+		return false, nil
 	}
 
 	words := strings.Split(cmt.GetBody(), " ")
 	firstWord := words[0]
+	action, ok := commandActions[firstWord]
+	if !ok {
+		return false, nil
+	}
+	rec := newAuditRecord(ev, repoCfg, firstWord, action)
+	if repoCfg.DryRun {
+		logger.Info("dry run: a slash command would be run", zap.String("slash_command", firstWord), zap.Strings("slash_command_args", words[1:]))
+		rec.Result = audit.ResultDryRun
+		audit.Write(ctx, logger, sink, rec)
+		return true, nil
+	}
+	var err error
 	switch firstWord {
 	case "/rerun-workflow":
-		rerunWorkflows(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
-		return true
+		rec.RunIDs, err = rerunWorkflows(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
 	case "/rerun-failed-job":
-		rerunFailedJobs(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
-		return true
+		rec.RunIDs, err = rerunFailedJobs(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
 	case "/cancel":
-		cancelWorkflows(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
-		return true
+		rec.RunIDs, err = cancelWorkflows(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
 	case "/rerun-job":
-		rerunJobs(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
-		return true
+		rec.JobIDs, err = rerunJobs(ctx, logger, repoCfg.GitHub, repoCfg.RepoOwner, repoCfg.CIRepoName, words[1:])
 	}
-	return false
+	rec.SetResult(err)
+	audit.Write(ctx, logger, sink, rec)
+	return true, err
+}
+
+// commandActions is a map of slash commands and audit actions
+var commandActions = map[string]string{ //nolint:gochecknoglobals
+	"/rerun-workflow":   audit.ActionRerunWorkflow,
+	"/rerun-failed-job": audit.ActionRerunFailedJobs,
+	"/cancel":           audit.ActionCancelWorkflow,
+	"/rerun-job":        audit.ActionRerunJob,
+}
+
+func newAuditRecord(ev *domain.Event, repoCfg *config.Repo, cmd, action string) *audit.Record {
+	rec := audit.NewRecord(ev, action)
+	// the commenter runs the command
+	rec.Sender = ev.Payload.Comment.GetUser().GetLogin()
+	rec.TargetRepo = repoCfg.RepoOwner + "/" + repoCfg.CIRepoName
+	rec.Command = cmd
+	return rec
 }
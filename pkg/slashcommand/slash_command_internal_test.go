@@ -0,0 +1,159 @@
+package slashcommand
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gha-trigger/gha-trigger/pkg/audit"
+	"github.com/gha-trigger/gha-trigger/pkg/config"
+	"github.com/gha-trigger/gha-trigger/pkg/domain"
+	"github.com/gha-trigger/gha-trigger/pkg/github"
+	"github.com/gha-trigger/gha-trigger/pkg/util"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+)
+
+type auditSink struct {
+	recs []*audit.Record
+}
+
+func (sink *auditSink) Write(ctx context.Context, rec *audit.Record) error {
+	sink.recs = append(sink.recs, rec)
+	return nil
+}
+
+func Test_handle(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/gha-trigger/example-ci/actions/runs/1/cancel":
+			w.WriteHeader(http.StatusAccepted)
+		case "/repos/gha-trigger/example-ci/actions/jobs/3/rerun":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	v3 := github.NewV3Client(srv.Client())
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3.BaseURL = baseURL
+	gh := github.New(v3)
+
+	tests := []struct {
+		name    string
+		body    string
+		dryRun  bool
+		handled bool
+		wantErr bool
+		exp     []*audit.Record
+	}{
+		{
+			name: "not a slash command",
+			body: "lgtm",
+		},
+		{
+			name:    "cancel",
+			body:    "/cancel 1 2",
+			handled: true,
+			wantErr: true,
+			exp: []*audit.Record{
+				{
+					Action:     audit.ActionCancelWorkflow,
+					Event:      "issue_comment",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					TargetRepo: "gha-trigger/example-ci",
+					Command:    "/cancel",
+					RunIDs:     []int64{1},
+					Result:     audit.ResultFailure,
+					Error:      "1 API calls failed",
+				},
+			},
+		},
+		{
+			name:    "rerun job",
+			body:    "/rerun-job 3",
+			handled: true,
+			exp: []*audit.Record{
+				{
+					Action:     audit.ActionRerunJob,
+					Event:      "issue_comment",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					TargetRepo: "gha-trigger/example-ci",
+					Command:    "/rerun-job",
+					JobIDs:     []int64{3},
+					Result:     audit.ResultSuccess,
+				},
+			},
+		},
+		{
+			name:    "dry run",
+			body:    "/cancel 1",
+			dryRun:  true,
+			handled: true,
+			exp: []*audit.Record{
+				{
+					Action:     audit.ActionCancelWorkflow,
+					Event:      "issue_comment",
+					Sender:     "octocat",
+					SourceRepo: "gha-trigger/example",
+					TargetRepo: "gha-trigger/example-ci",
+					Command:    "/cancel",
+					Result:     audit.ResultDryRun,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	logger, _ := zap.NewProduction()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sink := &auditSink{}
+			repoCfg := &config.Repo{
+				RepoOwner:  "gha-trigger",
+				CIRepoName: "example-ci",
+				DryRun:     tt.dryRun,
+				GitHub:     gh,
+			}
+			ev := &domain.Event{
+				Type: "issue_comment",
+				Payload: &domain.Payload{
+					Repo: &github.Repository{
+						FullName: util.StrP("gha-trigger/example"),
+					},
+					Comment: &github.IssueComment{
+						Body:    util.StrP(tt.body),
+						HTMLURL: util.StrP("https://github.com/gha-trigger/example/pull/1#issuecomment-1"),
+						User: &github.User{
+							Login: util.StrP("octocat"),
+						},
+					},
+				},
+			}
+			handled, err := handle(ctx, logger, sink, repoCfg, ev)
+			if handled != tt.handled {
+				t.Fatalf("handled: wanted %v, got %v", tt.handled, handled)
+			}
+			if err != nil && !tt.wantErr {
+				t.Fatal(err)
+			}
+			if err == nil && tt.wantErr {
+				t.Fatal("error must be returned")
+			}
+			if diff := cmp.Diff(tt.exp, sink.recs, cmpopts.IgnoreFields(audit.Record{}, "Time")); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
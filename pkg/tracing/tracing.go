@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/suzuki-shunsuke/go-osenv/osenv"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/gha-trigger/gha-trigger"
+
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Attribute keys shared by spans
+const (
+	AttrDeliveryID = attribute.Key("github.delivery_id")
+	AttrRepo       = attribute.Key("github.repo")
+	AttrEventType  = attribute.Key("github.event_type")
+)
+
+// Start starts a span by the global TracerProvider.
+// If tracing isn't set up, the span does nothing.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error to the span and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// NewTransport returns a transport which creates a span for each HTTP request.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
+
+// Provider flushes and shuts down spans. A nil Provider does nothing.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// Setup configures the span exporter by the environment variable TRACES_EXPORTER.
+//
+// - stdout: spans are written to the standard output
+// - otlp: spans are sent by OTLP over HTTP. The endpoint is configured by OTEL_EXPORTER_OTLP_ENDPOINT
+//
+// If TRACES_EXPORTER isn't set, spans aren't recorded.
+func Setup(ctx context.Context, osEnv osenv.OSEnv) (*Provider, error) {
+	var exporter sdktrace.SpanExporter
+	switch name := osEnv.Getenv("TRACES_EXPORTER"); name {
+	case "":
+		return nil, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("create a stdout exporter: %w", err)
+		}
+		exporter = exp
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create an OTLP exporter: %w", err)
+		}
+		exporter = exp
+	default:
+		return nil, fmt.Errorf("TRACES_EXPORTER is invalid: %s", name)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return &Provider{
+		provider: provider,
+	}, nil
+}
+
+// ForceFlush exports ended spans.
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	return p.provider.ForceFlush(ctx) //nolint:wrapcheck
+}
+
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	return p.provider.Shutdown(ctx) //nolint:wrapcheck
+}
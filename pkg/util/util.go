@@ -15,3 +15,11 @@ func StrP(s string) *string {
 func BoolP(b bool) *bool {
 	return &b
 }
+
+func IntP(i int) *int {
+	return &i
+}
+
+func Int64P(i int64) *int64 {
+	return &i
+}